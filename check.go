@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"unicode/utf8"
+)
+
+func runCheck(args []string) error {
+	flags := flag.NewFlagSet("vangen check", flag.ExitOnError)
+	filename := flags.String("config", "vangen.json", "vangen configuration `filename`")
+	configFormat := flags.String("config-format", "", configFormatUsage)
+	outputDir := flags.String("out", "vangen/", "output `directory` that static files have been written to")
+	discover := flags.Bool("discover", false, discoverUsage)
+	templates := flags.String("templates", "", templatesUsage)
+	assets := flags.String("assets", "", assetsUsage)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Check verifies that the files in the output directory are the files vangen would generate, printing a diff for each file that is missing, stale or unexpected.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "  vangen check [-config=vangen.json] [-out=vangen/]\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	g, err := loadGenerator(*filename, *configFormat, *discover, *templates, *assets)
+	if err != nil {
+		return err
+	}
+
+	files, err := g.FS()
+	if err != nil {
+		return err
+	}
+
+	n, err := check(os.Stdout, files, *outputDir)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		return fmt.Errorf("%d files in %s are not up to date, run vangen to regenerate them", n, *outputDir)
+	}
+	return nil
+}
+
+// check compares the files with the files in outputDir, writing a unified diff
+// to w for each file that differs, is missing from outputDir, or was
+// previously generated into outputDir but would not be generated now. Files
+// previously generated are those listed in the manifest, the same files that
+// generating removes. It returns the number of files that differ.
+func check(w io.Writer, files fs.FS, outputDir string) (int, error) {
+	expected := map[string][]byte{}
+	err := fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		expected[name], err = fs.ReadFile(files, name)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	actual := map[string][]byte{}
+	manifest, err := readManifest(outputDir)
+	if err != nil {
+		return 0, fmt.Errorf("reading manifest: %w", err)
+	}
+	listed := map[string]bool{}
+	for _, name := range manifest {
+		listed[name] = true
+	}
+	for name := range expected {
+		listed[name] = true
+	}
+	names := []string{}
+	for name := range listed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return 0, err
+		}
+		actual[name] = data
+	}
+
+	n := 0
+	for _, name := range names {
+		e, eok := expected[name]
+		a, aok := actual[name]
+		nameA, nameB := filepath.Join(outputDir, name), filepath.Join(outputDir, name)
+		switch {
+		case !aok:
+			nameA = "/dev/null"
+		case !eok:
+			nameB = "/dev/null"
+		}
+		if !utf8.Valid(a) || !utf8.Valid(e) {
+			// Assets such as images are not diffed line by line.
+			if bytes.Equal(a, e) && aok == eok {
+				continue
+			}
+			n++
+			fmt.Fprintf(w, "Binary files %s and %s differ\n", nameA, nameB)
+			continue
+		}
+		diff := unifiedDiff(nameA, nameB, string(a), string(e))
+		if diff == "" && aok == eok {
+			continue
+		}
+		n++
+		if diff == "" {
+			// An empty file is missing or unexpected but has no lines to diff.
+			diff = fmt.Sprintf("--- %s\n+++ %s\n", nameA, nameB)
+		}
+		fmt.Fprint(w, diff)
+	}
+	return n, nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestCheck(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":          {Data: []byte("index\n")},
+		"pkg1/index.html":     {Data: []byte("pkg1\n")},
+		"pkg2/index.html":     {Data: []byte("pkg2\n")},
+		"pkg2/sub/index.html": {Data: []byte("pkg2/sub\n")},
+		"logo.0123456789.png": {Data: []byte("\x89PNG\r\n\x1a\n\xff")},
+	}
+
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{
+		"index.html":      "index\n",
+		"pkg1/index.html": "pkg1 stale\n",
+		"pkg3/index.html": "pkg3\n",
+		"CNAME":           "example.com\n",
+		"pkg2/index.html": "pkg2\n",
+	})
+
+	err := writeManifest(outputDir, []string{"index.html", "pkg1/index.html", "pkg2/index.html", "pkg3/index.html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	n, err := check(&out, files, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g, w := n, 4; g != w {
+		t.Errorf("Got %d files not up to date, want %d", g, w)
+	}
+
+	expectedOut := strings.ReplaceAll(`Binary files /dev/null and OUT/logo.0123456789.png differ
+--- OUT/pkg1/index.html
++++ OUT/pkg1/index.html
+@@ -1 +1 @@
+-pkg1 stale
++pkg1
+--- /dev/null
++++ OUT/pkg2/sub/index.html
+@@ -0,0 +1 @@
++pkg2/sub
+--- OUT/pkg3/index.html
++++ /dev/null
+@@ -1 +0,0 @@
+-pkg3
+`, "OUT", outputDir)
+	if g, w := out.String(), expectedOut; g != w {
+		t.Errorf("Got output:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestCheckMissingOutputDir(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html": {Data: []byte("index\n")},
+	}
+
+	var out strings.Builder
+	n, err := check(&out, files, filepath.Join(t.TempDir(), "vangen"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := n, 1; g != w {
+		t.Errorf("Got %d files not up to date, want %d", g, w)
+	}
+}
+
+func TestCheckManifest(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":      {Data: []byte("index\n")},
+		"pkg1/index.html": {Data: []byte("pkg1\n")},
+	}
+
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{
+		"index.html":      "index\n",
+		"pkg1/index.html": "pkg1\n",
+		"pkg2/index.html": "pkg2\n",
+		"docs/index.html": "hand written\n",
+	})
+	err := writeManifest(outputDir, []string{"index.html", "pkg1/index.html", "pkg2/index.html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	n, err := check(&out, files, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g, w := n, 1; g != w {
+		t.Errorf("Got %d files not up to date, want %d", g, w)
+	}
+
+	expectedOut := strings.ReplaceAll(`--- OUT/pkg2/index.html
++++ /dev/null
+@@ -1 +0,0 @@
+-pkg2
+`, "OUT", outputDir)
+	if g, w := out.String(), expectedOut; g != w {
+		t.Errorf("Got output:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestCheckNoManifest(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html": {Data: []byte("index\n")},
+	}
+
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{"index.html": "index\n", "oldpkg/index.html": "oldpkg\n"})
+
+	// Generating removes nothing without a manifest, so check reports
+	// nothing that generating would not fix.
+	p, err := planOutput(files, outputDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.removes) != 0 {
+		t.Errorf("Got removes %q, want none", p.removes)
+	}
+	var out strings.Builder
+	n, err := check(&out, files, outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := n, 0; g != w {
+		t.Errorf("Got %d files not up to date, want %d:\n%s", g, w, out.String())
+	}
+}
+
+// writeFiles writes files to dir, making any directories they need. The
+// files are keyed by slash separated paths relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(p), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(p, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"4d63.com/vangen/vanity"
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// marshalConfig returns the config in the format, leaving out fields that are
+// not set and writing subs and versions that have only a name in their string
+// form.
+func marshalConfig(c vanity.Config, format string) ([]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+	if err != nil {
+		return nil, err
+	}
+	v, _ = pruneJSON(v)
+	if v == nil {
+		v = map[string]interface{}{}
+	}
+
+	switch format {
+	case vanity.FormatJSON:
+		data, err = json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	case vanity.FormatYAML:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		err = enc.Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case vanity.FormatTOML:
+		var buf bytes.Buffer
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = ""
+		err = enc.Encode(v)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported config format %q", format)
+	}
+}
+
+// pruneJSON removes empty strings, false values, and empty lists and objects
+// from v. It returns false if v is itself empty.
+func pruneJSON(v interface{}) (interface{}, bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			e, ok := pruneJSON(e)
+			if !ok {
+				delete(v, k)
+				continue
+			}
+			v[k] = e
+		}
+		if len(v) == 1 {
+			for _, k := range []string{"name", "version"} {
+				if s, ok := v[k].(string); ok {
+					return s, true
+				}
+			}
+		}
+		return v, len(v) > 0
+	case []interface{}:
+		l := make([]interface{}, 0, len(v))
+		for _, e := range v {
+			if e, ok := pruneJSON(e); ok {
+				l = append(l, e)
+			}
+		}
+		return l, len(l) > 0
+	case string:
+		return v, v != ""
+	case bool:
+		return v, v
+	default:
+		return v, v != nil
+	}
+}
+
+// writeNewFile writes data to the file name, which must not already exist.
+func writeNewFile(name string, data []byte) error {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	return err
+}
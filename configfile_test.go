@@ -0,0 +1,99 @@
+package main
+
+import (
+	"testing"
+
+	"4d63.com/vangen/vanity"
+)
+
+func TestMarshalConfig(t *testing.T) {
+	c := vanity.Config{
+		Domain: "example.com",
+		Repositories: []vanity.Repository{
+			{
+				Prefix:   "pkg1",
+				Subs:     []vanity.Sub{{Name: "sub1"}, {Name: "sub2", Hidden: true}},
+				URL:      "https://github.com/example/go-pkg1",
+				Versions: []vanity.Version{{Version: "v2"}, {Version: "v3", Branch: "v3"}},
+			},
+		},
+	}
+	data, err := marshalConfig(c, vanity.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `{
+  "domain": "example.com",
+  "repositories": [
+    {
+      "prefix": "pkg1",
+      "subs": [
+        "sub1",
+        {
+          "hidden": true,
+          "name": "sub2"
+        }
+      ],
+      "url": "https://github.com/example/go-pkg1",
+      "versions": [
+        "v2",
+        {
+          "branch": "v3",
+          "version": "v3"
+        }
+      ]
+    }
+  ]
+}
+`
+	if g, w := string(data), expected; g != w {
+		t.Errorf("Got:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestMarshalConfigFormats(t *testing.T) {
+	c := vanity.Config{
+		Domain: "example.com",
+		Repositories: []vanity.Repository{
+			{
+				Prefix: "pkg1",
+				Subs:   []vanity.Sub{{Name: "sub1"}},
+				URL:    "https://github.com/example/go-pkg1",
+			},
+		},
+	}
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: vanity.FormatYAML,
+			expected: `domain: example.com
+repositories:
+  - prefix: pkg1
+    subs:
+      - sub1
+    url: https://github.com/example/go-pkg1
+`,
+		},
+		{
+			format: vanity.FormatTOML,
+			expected: `domain = "example.com"
+
+[[repositories]]
+prefix = "pkg1"
+subs = ["sub1"]
+url = "https://github.com/example/go-pkg1"
+`,
+		},
+	}
+	for _, tc := range testCases {
+		data, err := marshalConfig(c, tc.format)
+		if err != nil {
+			t.Fatalf("Test case %q got error %v", tc.format, err)
+		}
+		if g, w := string(data), tc.expected; g != w {
+			t.Errorf("Test case %q got:\n%s\nwant:\n%s", tc.format, g, w)
+		}
+	}
+}
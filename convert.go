@@ -0,0 +1,412 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"4d63.com/vangen/vanity"
+	"gopkg.in/yaml.v3"
+)
+
+// Config formats that convert reads and writes.
+const (
+	formatVangen       = "vangen"
+	formatGovanityurls = "govanityurls"
+	formatSally        = "sally"
+)
+
+func runConvert(args []string) error {
+	flags := flag.NewFlagSet("vangen convert", flag.ExitOnError)
+	from := flags.String("from", formatVangen, "`format` of the input config, one of vangen, govanityurls or sally")
+	to := flags.String("to", formatVangen, "`format` of the output config, one of vangen, govanityurls or sally")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Convert converts a config between vangen and the govanityurls and sally formats, printing a warning for each setting that has no equivalent in the output format.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "  vangen convert [-from=format] [-to=format] input output\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return errors.New("convert requires the input and output config files")
+	}
+	input, output := flags.Arg(0), flags.Arg(1)
+	if *from == *to {
+		return fmt.Errorf("-from and -to are both %s, one of them must be another format", *from)
+	}
+
+	var c vanity.Config
+	var warnings []string
+	switch *from {
+	case formatVangen:
+		var err error
+		c, err = loadConfig(input, "", false)
+		if err != nil {
+			return err
+		}
+	case formatGovanityurls, formatSally:
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if *from == formatGovanityurls {
+			c, warnings, err = parseGovanityurls(f)
+		} else {
+			c, warnings, err = parseSally(f)
+		}
+		if err != nil {
+			return fmt.Errorf("parsing %s config %s: %w", *from, input, err)
+		}
+	default:
+		return fmt.Errorf("unsupported format %q, must be one of vangen, govanityurls or sally", *from)
+	}
+
+	var data []byte
+	var err error
+	switch *to {
+	case formatVangen:
+		data, err = marshalConfig(c, vanity.FormatForFilename(output))
+	case formatGovanityurls:
+		var w []string
+		data, w, err = marshalGovanityurls(c)
+		warnings = append(warnings, w...)
+	case formatSally:
+		var w []string
+		data, w, err = marshalSally(c)
+		warnings = append(warnings, w...)
+	default:
+		return fmt.Errorf("unsupported format %q, must be one of vangen, govanityurls or sally", *to)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	return writeNewFile(output, data)
+}
+
+// govanityurlsConfig is the vanity.yaml config of govanityurls.
+type govanityurlsConfig struct {
+	Host        string                      `yaml:"host,omitempty"`
+	CacheMaxAge *int64                      `yaml:"cache_max_age,omitempty"`
+	Paths       map[string]govanityurlsPath `yaml:"paths"`
+}
+
+type govanityurlsPath struct {
+	Repo    string `yaml:"repo"`
+	Display string `yaml:"display,omitempty"`
+	VCS     string `yaml:"vcs,omitempty"`
+}
+
+// sallyConfig is the sally.yaml config of sally.
+type sallyConfig struct {
+	URL      string                  `yaml:"url"`
+	Godoc    sallyGodoc              `yaml:"godoc,omitempty"`
+	Packages map[string]sallyPackage `yaml:"packages"`
+}
+
+type sallyGodoc struct {
+	Host string `yaml:"host,omitempty"`
+}
+
+type sallyPackage struct {
+	Repo        string `yaml:"repo"`
+	Branch      string `yaml:"branch,omitempty"`
+	URL         string `yaml:"url,omitempty"`
+	Description string `yaml:"description,omitempty"`
+	VCS         string `yaml:"vcs,omitempty"`
+}
+
+// parseGovanityurls reads a govanityurls config from r and returns the
+// equivalent vangen config, and a warning for each setting that has no
+// equivalent.
+func parseGovanityurls(r io.Reader) (vanity.Config, []string, error) {
+	var gc govanityurlsConfig
+	err := yaml.NewDecoder(r).Decode(&gc)
+	if err != nil && err != io.EOF {
+		return vanity.Config{}, nil, err
+	}
+
+	var warnings []string
+	c := vanity.Config{Domain: gc.Host}
+	if gc.Host == "" {
+		warnings = append(warnings, "host is not set, govanityurls uses the host of each request but vangen requires domain to be set")
+	}
+	if gc.CacheMaxAge != nil {
+		warnings = append(warnings, "cache_max_age has no equivalent in vangen and is ignored")
+	}
+
+	for _, p := range sortedKeys(gc.Paths) {
+		gp := gc.Paths[p]
+		r := vanity.Repository{
+			Prefix: strings.Trim(p, "/"),
+			Type:   gp.VCS,
+			URL:    gp.Repo,
+		}
+		if gp.Display != "" {
+			fields := strings.Fields(gp.Display)
+			if len(fields) == 3 {
+				r.SourceURLs = vanity.SourceURLs{Home: fields[0], Dir: fields[1], File: fields[2]}
+			} else {
+				warnings = append(warnings, fmt.Sprintf("paths[%q].display %q is not three URLs and is ignored", p, gp.Display))
+			}
+		}
+		c.Repositories = append(c.Repositories, r)
+	}
+	if len(gc.Paths) > 0 {
+		warnings = append(warnings, subPackagesWarning("paths", sortedKeys(gc.Paths), formatGovanityurls))
+	}
+	return c, warnings, nil
+}
+
+// parseSally reads a sally config from r and returns the equivalent vangen
+// config, and a warning for each setting that has no equivalent.
+func parseSally(r io.Reader) (vanity.Config, []string, error) {
+	var sc sallyConfig
+	err := yaml.NewDecoder(r).Decode(&sc)
+	if err != nil && err != io.EOF {
+		return vanity.Config{}, nil, err
+	}
+
+	var warnings []string
+	c := vanity.Config{
+		Domain:     sc.URL,
+		DocsDomain: sc.Godoc.Host,
+		// Sally always serves an index of its packages.
+		Index: true,
+	}
+	for _, name := range sortedKeys(sc.Packages) {
+		sp := sc.Packages[name]
+		r := vanity.Repository{
+			Prefix:      name,
+			Type:        sp.VCS,
+			URL:         sp.Repo,
+			Branch:      sp.Branch,
+			Description: sp.Description,
+		}
+		// Sally's repos are written without a scheme.
+		if r.URL != "" && !strings.Contains(r.URL, "://") {
+			r.URL = "https://" + r.URL
+		}
+		if sp.URL != "" && sp.URL != sc.URL {
+			warnings = append(warnings, fmt.Sprintf("packages[%q].url %q has no equivalent in vangen, which serves every package at domain %q", name, sp.URL, sc.URL))
+		}
+		c.Repositories = append(c.Repositories, r)
+	}
+	if len(sc.Packages) > 0 {
+		warnings = append(warnings, subPackagesWarning("packages", sortedKeys(sc.Packages), formatSally))
+	}
+	return c, warnings, nil
+}
+
+// subPackagesWarning returns the warning that the packages below the paths
+// in the field of a govanityurls or sally config, which those tools serve
+// without them being listed, are not generated by vangen unless they are
+// subs.
+func subPackagesWarning(field string, paths []string, format string) string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = fmt.Sprintf("%q", p)
+	}
+	return fmt.Sprintf("%s %s: packages below them are served by %s but only generated by vangen when listed in subs or found with clone and discover, or when served with vangen serve", field, strings.Join(quoted, ", "), format)
+}
+
+// govanityurlsVCS are the VCS types that govanityurls supports.
+var govanityurlsVCS = map[string]bool{"bzr": true, "git": true, "hg": true, "svn": true}
+
+// marshalGovanityurls returns the govanityurls config equivalent to c, and a
+// warning for each setting that has no equivalent. The repository, VCS and
+// source URLs of each path are those in the pages vangen generates, so types
+// and source URLs that vangen infers are written out. Paths with a VCS that
+// govanityurls does not support, such as repositories served by a module
+// proxy, are left out.
+func marshalGovanityurls(c vanity.Config) ([]byte, []string, error) {
+	var warnings []string
+	gc := govanityurlsConfig{Host: c.Domain, Paths: map[string]govanityurlsPath{}}
+	if c.DocsDomain != "" {
+		warnings = append(warnings, "docsDomain has no equivalent in govanityurls and is ignored")
+	}
+	warnings = append(warnings, unsupportedConfigFields(c, formatGovanityurls)...)
+
+	for _, r := range c.Repositories {
+		warnings = append(warnings, unsupportedRepositoryFields(r, formatGovanityurls)...)
+		pkgs := []string{r.Prefix}
+		for i, v := range r.Versions {
+			// Versions in a major version subdirectory are served by the
+			// path for the repository, but versions in another repository
+			// need their own path.
+			switch {
+			case v.URL != "":
+				pkgs = append(pkgs, r.VersionPath(i))
+			case v.Branch != "":
+				warnings = append(warnings, fmt.Sprintf("repository %q: versions[%d].branch has no equivalent in govanityurls and is ignored", r.Prefix, i))
+			}
+		}
+		for _, pkg := range pkgs {
+			goImport, goSource, err := generatedMetaTags(c, pkg)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(goImport) == 4 {
+				warnings = append(warnings, fmt.Sprintf("repository %q: subdir has no equivalent in govanityurls and is ignored", r.Prefix))
+			}
+			if !govanityurlsVCS[goImport[1]] {
+				warnings = append(warnings, fmt.Sprintf("repository %q: govanityurls does not support vcs %q, the path /%s is left out", r.Prefix, goImport[1], pkg))
+				continue
+			}
+			gp := govanityurlsPath{Repo: goImport[2], VCS: goImport[1]}
+			if goSource[1] != "_" || goSource[2] != "_" || goSource[3] != "_" {
+				gp.Display = strings.Join(goSource[1:], " ")
+			}
+			gc.Paths["/"+pkg] = gp
+		}
+	}
+
+	data, err := marshalYAML(gc)
+	return data, warnings, err
+}
+
+// marshalSally returns the sally config equivalent to c, and a warning for
+// each setting that has no equivalent.
+func marshalSally(c vanity.Config) ([]byte, []string, error) {
+	var warnings []string
+	sc := sallyConfig{URL: c.Domain, Godoc: sallyGodoc{Host: c.DocsDomain}, Packages: map[string]sallyPackage{}}
+	if !c.Index {
+		warnings = append(warnings, "index is not set, but sally always serves an index")
+	}
+	warnings = append(warnings, unsupportedConfigFields(c, formatSally)...)
+
+	for _, r := range c.Repositories {
+		if r.Prefix == "" {
+			warnings = append(warnings, fmt.Sprintf("repository %q: sally cannot serve a package at the root of the domain, the repository is left out", r.Prefix))
+			continue
+		}
+		warnings = append(warnings, unsupportedRepositoryFields(r, formatSally)...)
+		if r.SourceURLs != (vanity.SourceURLs{}) {
+			warnings = append(warnings, fmt.Sprintf("repository %q: source has no equivalent in sally and is ignored", r.Prefix))
+		}
+		for i, v := range r.Versions {
+			if v.URL != "" || v.Branch != "" {
+				warnings = append(warnings, fmt.Sprintf("repository %q: versions[%d] has its own url or branch, which have no equivalent in sally and are ignored", r.Prefix, i))
+			}
+		}
+		branch := r.Branch
+		if branch == "" {
+			branch = c.Branch
+		}
+		if branch == vanity.BranchClone {
+			branch = ""
+		}
+		vcs := r.Type
+		if vcs == "git" {
+			vcs = ""
+		}
+		sc.Packages[r.Prefix] = sallyPackage{
+			Repo:        strings.TrimPrefix(r.URL, "https://"),
+			Branch:      branch,
+			Description: r.Description,
+			VCS:         vcs,
+		}
+	}
+
+	data, err := marshalYAML(sc)
+	return data, warnings, err
+}
+
+// unsupportedConfigFields returns a warning for each field set on c, other
+// than its repositories, that has no equivalent in either govanityurls or
+// sally. The proxy and hosts have no equivalent in sally. Govanityurls has
+// neither either, but the hosts are used to infer the VCS and source URLs
+// written out for it, and marshalGovanityurls warns about each repository
+// served by the proxy.
+func unsupportedConfigFields(c vanity.Config, format string) []string {
+	var warnings []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"theme", c.Theme != ""},
+		{"templates", c.Templates != ""},
+		{"assets", c.Assets != ""},
+		{"sections", len(c.Sections) > 0},
+		{"proxy", c.Proxy != "" && c.Proxy != "direct" && format == formatSally},
+		{"hosts", len(c.Hosts) > 0 && format == formatSally},
+	} {
+		if f.set {
+			warnings = append(warnings, fmt.Sprintf("%s has no equivalent in %s and is ignored", f.name, format))
+		}
+	}
+	return warnings
+}
+
+// unsupportedRepositoryFields returns a warning for each field set on r that
+// has no equivalent in either govanityurls or sally.
+func unsupportedRepositoryFields(r vanity.Repository, format string) []string {
+	var warnings []string
+	add := func(field string) {
+		warnings = append(warnings, fmt.Sprintf("repository %q: %s has no equivalent in %s and is ignored", r.Prefix, field, format))
+	}
+	if r.Hidden {
+		add("hidden")
+	}
+	if r.Main {
+		add("main")
+	}
+	if r.Website.URL != "" {
+		add("website")
+	}
+	if r.Description != "" && format == formatGovanityurls {
+		add("description")
+	}
+	if len(r.Tags) > 0 {
+		add("tags")
+	}
+	if r.Proxy != "" && r.Proxy != "direct" && format == formatSally {
+		add("proxy")
+	}
+	if r.Subdir != "" && format == formatSally {
+		add("subdir")
+	}
+	for _, s := range r.Subs {
+		if s.Hidden {
+			add(fmt.Sprintf("subs %q hidden", s.Name))
+		}
+		if s.Description != "" {
+			add(fmt.Sprintf("subs %q description", s.Name))
+		}
+		if len(s.Tags) > 0 {
+			add(fmt.Sprintf("subs %q tags", s.Name))
+		}
+	}
+	return warnings
+}
+
+func marshalYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	err := enc.Encode(v)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
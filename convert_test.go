@@ -0,0 +1,257 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"4d63.com/vangen/vanity"
+)
+
+func TestParseGovanityurls(t *testing.T) {
+	const input = `host: example.com
+cache_max_age: 3600
+paths:
+  /pkg1:
+    repo: https://github.com/example/go-pkg1
+  /pkg2:
+    repo: https://example.com/hg/go-pkg2
+    vcs: hg
+    display: "https://example.com/hg/go-pkg2 https://example.com/hg/go-pkg2/browse{/dir} https://example.com/hg/go-pkg2/browse{/dir}/{file}#L{line}"
+  /pkg3:
+    repo: https://example.com/git/pkg3
+    vcs: git
+    display: "https://example.com/git/pkg3"
+`
+	c, warnings, err := parseGovanityurls(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedConfig := vanity.Config{
+		Domain: "example.com",
+		Repositories: []vanity.Repository{
+			{
+				Prefix: "pkg1",
+				URL:    "https://github.com/example/go-pkg1",
+			},
+			{
+				Prefix: "pkg2",
+				Type:   "hg",
+				URL:    "https://example.com/hg/go-pkg2",
+				SourceURLs: vanity.SourceURLs{
+					Home: "https://example.com/hg/go-pkg2",
+					Dir:  "https://example.com/hg/go-pkg2/browse{/dir}",
+					File: "https://example.com/hg/go-pkg2/browse{/dir}/{file}#L{line}",
+				},
+			},
+			{
+				Prefix: "pkg3",
+				Type:   "git",
+				URL:    "https://example.com/git/pkg3",
+			},
+		},
+	}
+	if !reflect.DeepEqual(c, expectedConfig) {
+		t.Errorf("Got config:\n%#v\nwant:\n%#v", c, expectedConfig)
+	}
+
+	expectedWarnings := []string{
+		`cache_max_age has no equivalent in vangen and is ignored`,
+		`paths["/pkg3"].display "https://example.com/git/pkg3" is not three URLs and is ignored`,
+		`paths "/pkg1", "/pkg2", "/pkg3": packages below them are served by govanityurls but only generated by vangen when listed in subs or found with clone and discover, or when served with vangen serve`,
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Got warnings %q, want %q", warnings, expectedWarnings)
+	}
+}
+
+func TestParseSally(t *testing.T) {
+	const input = `url: go.example.com
+godoc:
+  host: godoc.example.com
+packages:
+  pkg2:
+    repo: example.com/hg/go-pkg2
+    vcs: hg
+    description: Package two.
+  pkg1:
+    repo: github.com/example/go-pkg1
+    branch: main
+  pkg3:
+    repo: https://github.com/example/go-pkg3
+    url: other.example.com
+`
+	c, warnings, err := parseSally(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedConfig := vanity.Config{
+		Domain:     "go.example.com",
+		DocsDomain: "godoc.example.com",
+		Index:      true,
+		Repositories: []vanity.Repository{
+			{
+				Prefix: "pkg1",
+				URL:    "https://github.com/example/go-pkg1",
+				Branch: "main",
+			},
+			{
+				Prefix:      "pkg2",
+				Type:        "hg",
+				URL:         "https://example.com/hg/go-pkg2",
+				Description: "Package two.",
+			},
+			{
+				Prefix: "pkg3",
+				URL:    "https://github.com/example/go-pkg3",
+			},
+		},
+	}
+	if !reflect.DeepEqual(c, expectedConfig) {
+		t.Errorf("Got config:\n%#v\nwant:\n%#v", c, expectedConfig)
+	}
+
+	expectedWarnings := []string{
+		`packages["pkg3"].url "other.example.com" has no equivalent in vangen, which serves every package at domain "go.example.com"`,
+		`packages "pkg1", "pkg2", "pkg3": packages below them are served by sally but only generated by vangen when listed in subs or found with clone and discover, or when served with vangen serve`,
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Got warnings %q, want %q", warnings, expectedWarnings)
+	}
+}
+
+func TestMarshalGovanityurls(t *testing.T) {
+	c := vanity.Config{
+		Domain:     "example.com",
+		DocsDomain: "godoc.example.com",
+		Sections:   []vanity.Section{{Title: "All"}},
+		Repositories: []vanity.Repository{
+			{
+				Prefix:      "pkg1",
+				Description: "Package one.",
+				URL:         "https://github.com/example/go-pkg1",
+				Branch:      "main",
+				Hidden:      true,
+				Versions:    []vanity.Version{{Version: "v2", URL: "https://github.com/example/go-pkg1-v2"}},
+			},
+			{
+				Prefix: "pkg2",
+				Type:   "git",
+				URL:    "https://example.com/git/pkg2",
+			},
+			{
+				Prefix: "pkg3",
+				URL:    "https://github.com/example/go-pkg3",
+				Proxy:  "https://athens.example.com",
+			},
+			{
+				Prefix: "pkg4",
+				Type:   "fossil",
+				URL:    "https://example.com/fossil/pkg4",
+			},
+		},
+	}
+	data, warnings, err := marshalGovanityurls(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `host: example.com
+paths:
+  /pkg1:
+    repo: https://github.com/example/go-pkg1
+    display: https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/main{/dir} https://github.com/example/go-pkg1/blob/main{/dir}/{file}#L{line}
+    vcs: git
+  /pkg1/v2:
+    repo: https://github.com/example/go-pkg1-v2
+    display: https://github.com/example/go-pkg1-v2 https://github.com/example/go-pkg1-v2/tree/main{/dir} https://github.com/example/go-pkg1-v2/blob/main{/dir}/{file}#L{line}
+    vcs: git
+  /pkg2:
+    repo: https://example.com/git/pkg2
+    vcs: git
+`
+	if g, w := string(data), expected; g != w {
+		t.Errorf("Got:\n%s\nwant:\n%s", g, w)
+	}
+
+	expectedWarnings := []string{
+		`docsDomain has no equivalent in govanityurls and is ignored`,
+		`sections has no equivalent in govanityurls and is ignored`,
+		`repository "pkg1": hidden has no equivalent in govanityurls and is ignored`,
+		`repository "pkg1": description has no equivalent in govanityurls and is ignored`,
+		`repository "pkg3": govanityurls does not support vcs "mod", the path /pkg3 is left out`,
+		`repository "pkg4": govanityurls does not support vcs "fossil", the path /pkg4 is left out`,
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Got warnings %q, want %q", warnings, expectedWarnings)
+	}
+}
+
+func TestMarshalSally(t *testing.T) {
+	c := vanity.Config{
+		Domain:     "example.com",
+		DocsDomain: "godoc.example.com",
+		Branch:     "main",
+		Proxy:      "https://athens.example.com",
+		Hosts:      []vanity.Host{{Host: "git.example.com", Kind: "gitea"}},
+		Repositories: []vanity.Repository{
+			{
+				Prefix: "",
+				URL:    "https://github.com/example/go-root",
+			},
+			{
+				Prefix: "pkg1",
+				URL:    "https://github.com/example/go-pkg1",
+				Main:   true,
+				Proxy:  "direct",
+			},
+			{
+				Prefix:      "pkg2",
+				Type:        "hg",
+				URL:         "https://example.com/hg/go-pkg2",
+				Branch:      "default",
+				Description: "Package two.",
+				Tags:        []string{"vcs"},
+				SourceURLs: vanity.SourceURLs{
+					Home: "https://example.com/hg/go-pkg2",
+				},
+			},
+		},
+	}
+	data, warnings, err := marshalSally(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `url: example.com
+godoc:
+  host: godoc.example.com
+packages:
+  pkg1:
+    repo: github.com/example/go-pkg1
+    branch: main
+  pkg2:
+    repo: example.com/hg/go-pkg2
+    branch: default
+    description: Package two.
+    vcs: hg
+`
+	if g, w := string(data), expected; g != w {
+		t.Errorf("Got:\n%s\nwant:\n%s", g, w)
+	}
+
+	expectedWarnings := []string{
+		`index is not set, but sally always serves an index`,
+		`proxy has no equivalent in sally and is ignored`,
+		`hosts has no equivalent in sally and is ignored`,
+		`repository "": sally cannot serve a package at the root of the domain, the repository is left out`,
+		`repository "pkg1": main has no equivalent in sally and is ignored`,
+		`repository "pkg2": tags has no equivalent in sally and is ignored`,
+		`repository "pkg2": source has no equivalent in sally and is ignored`,
+	}
+	if !reflect.DeepEqual(warnings, expectedWarnings) {
+		t.Errorf("Got warnings %q, want %q", warnings, expectedWarnings)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// diffContext is the number of unchanged lines shown around each change in a
+// unified diff.
+const diffContext = 3
+
+type diffLine struct {
+	op   diffmatchpatch.Operation
+	text string
+}
+
+// unifiedDiff returns the changes from a to b in unified diff format, or an
+// empty string if they are equal.
+func unifiedDiff(nameA, nameB, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	dmp := diffmatchpatch.New()
+	charsA, charsB, lines := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(charsA, charsB, false), lines)
+
+	var dl []diffLine
+	for _, d := range diffs {
+		for _, l := range strings.SplitAfter(d.Text, "\n") {
+			if l != "" {
+				dl = append(dl, diffLine{op: d.Type, text: l})
+			}
+		}
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", nameA, nameB)
+
+	lineA, lineB := 1, 1
+	for i := 0; i < len(dl); {
+		if dl[i].op == diffmatchpatch.DiffEqual {
+			lineA++
+			lineB++
+			i++
+			continue
+		}
+
+		// Find the extent of the hunk, merging changes separated by fewer
+		// unchanged lines than would be shown as context on both sides.
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for j := i; j < len(dl); j++ {
+			if dl[j].op != diffmatchpatch.DiffEqual {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end += diffContext
+		if end > len(dl) {
+			end = len(dl)
+		}
+
+		startA, startB := lineA-(i-start), lineB-(i-start)
+		var lenA, lenB int
+		for _, l := range dl[start:end] {
+			if l.op != diffmatchpatch.DiffInsert {
+				lenA++
+			}
+			if l.op != diffmatchpatch.DiffDelete {
+				lenB++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(startA, lenA), hunkRange(startB, lenB))
+
+		for _, l := range dl[start:end] {
+			switch l.op {
+			case diffmatchpatch.DiffEqual:
+				sb.WriteByte(' ')
+			case diffmatchpatch.DiffDelete:
+				sb.WriteByte('-')
+			case diffmatchpatch.DiffInsert:
+				sb.WriteByte('+')
+			}
+			sb.WriteString(l.text)
+			if !strings.HasSuffix(l.text, "\n") {
+				sb.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+
+		for _, l := range dl[i:end] {
+			if l.op != diffmatchpatch.DiffInsert {
+				lineA++
+			}
+			if l.op != diffmatchpatch.DiffDelete {
+				lineB++
+			}
+		}
+		i = end
+	}
+
+	return sb.String()
+}
+
+func hunkRange(start, length int) string {
+	if length == 0 {
+		start--
+	}
+	if length == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		description  string
+		a            string
+		b            string
+		expectedDiff string
+	}{
+		{
+			description:  "equal",
+			a:            "a\nb\nc\n",
+			b:            "a\nb\nc\n",
+			expectedDiff: "",
+		},
+		{
+			description: "change",
+			a:           "1\n2\n3\n4\n5\n6\n7\n8\n9\n",
+			b:           "1\n2\n3\n4\nfive\n6\n7\n8\n9\n",
+			expectedDiff: `--- a
++++ b
+@@ -2,7 +2,7 @@
+ 2
+ 3
+ 4
+-5
++five
+ 6
+ 7
+ 8
+`,
+		},
+		{
+			description: "separate hunks",
+			a:           "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			b:           "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n",
+			expectedDiff: `--- a
++++ b
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,3 @@
+ 9
+ 10
+ 11
+-12
+`,
+		},
+		{
+			description: "from empty",
+			a:           "",
+			b:           "a\nb",
+			expectedDiff: `--- a
++++ b
+@@ -0,0 +1,2 @@
++a
++b
+\ No newline at end of file
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		diff := unifiedDiff("a", "b", tc.a, tc.b)
+		if diff != tc.expectedDiff {
+			t.Errorf("Test case %q got diff:\n%s\nwant:\n%s", tc.description, diff, tc.expectedDiff)
+		}
+	}
+}
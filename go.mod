@@ -2,11 +2,11 @@ module 4d63.com/vangen
 
 go 1.21.0
 
-require github.com/sergi/go-diff v1.0.0
-
 require (
-	github.com/davecgh/go-spew v1.1.0 // indirect
-	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/objx v0.1.0 // indirect
-	github.com/stretchr/testify v1.3.0 // indirect
+	github.com/BurntSushi/toml v1.6.0
+	github.com/sergi/go-diff v1.0.0
+	golang.org/x/mod v0.17.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/stretchr/testify v1.3.0 // indirect
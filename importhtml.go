@@ -0,0 +1,322 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"4d63.com/vangen/vanity"
+)
+
+func runImportHTML(args []string) error {
+	flags := flag.NewFlagSet("vangen import-html", flag.ExitOnError)
+	filename := flags.String("config", "vangen.json", "vangen configuration `filename` to write, which must not exist")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Import-html writes a vangen configuration that reproduces the go-import and go-source meta tags of the HTML pages in a directory, such as an existing vanity import path site.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "  vangen import-html [-config=vangen.json] directory\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return errors.New("import-html requires the directory to import")
+	}
+
+	c, conflicts, err := importHTML(os.DirFS(flags.Arg(0)))
+	if err != nil {
+		return err
+	}
+	for _, conflict := range conflicts {
+		fmt.Fprintln(os.Stderr, conflict)
+	}
+
+	data, err := marshalConfig(c, vanity.FormatForFilename(*filename))
+	if err != nil {
+		return err
+	}
+	err = writeNewFile(*filename, data)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%d pages have meta tags that conflict and are not reproduced by %s", len(conflicts), *filename)
+	}
+	return nil
+}
+
+// importedPage is a page found in the directory being imported.
+type importedPage struct {
+	name, pkg          string
+	domain, root       string
+	goImport, goSource []string
+}
+
+// importedRepository is a repository found in the pages being imported.
+type importedRepository struct {
+	goImport, goSource []string
+	pages              []string
+}
+
+// importHTML returns a config that generates the go-import and go-source meta
+// tags of the HTML pages in fsys. Pages are grouped into a repository for
+// each import root, with the other pages as subs. A message is returned for
+// each page whose tags conflict with the other pages, which is left out of
+// the config.
+func importHTML(fsys fs.FS) (vanity.Config, []string, error) {
+	var c vanity.Config
+	var conflicts []string
+	var pages []importedPage
+	domains := map[string]int{}
+
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(name) != ".html" {
+			return nil
+		}
+		pkg := strings.TrimSuffix(name, ".html")
+		if path.Base(name) == "index.html" {
+			pkg = path.Dir(name)
+		}
+		if pkg == "." {
+			pkg = ""
+		}
+
+		f, err := fsys.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		imports, sources, err := parseMetaTags(f)
+		if err != nil {
+			conflicts = append(conflicts, fmt.Sprintf("%s: parsing meta tags: %v", name, err))
+			return nil
+		}
+		if len(imports) == 0 {
+			if pkg == "" {
+				c.Index = true
+			}
+			return nil
+		}
+		if len(imports) > 1 {
+			conflicts = append(conflicts, fmt.Sprintf("%s: has %d go-import meta tags", name, len(imports)))
+			return nil
+		}
+		p := importedPage{name: name, pkg: pkg, goImport: imports[0]}
+		p.goSource = []string{p.goImport[0], "_", "_", "_"}
+		if len(sources) > 0 {
+			p.goSource = sources[0]
+		}
+		if p.goSource[0] != p.goImport[0] {
+			conflicts = append(conflicts, fmt.Sprintf("%s: go-source prefix %s is not the go-import prefix %s", name, p.goSource[0], p.goImport[0]))
+			return nil
+		}
+		p.domain, p.root = splitImportPrefix(p.goImport[0], pkg)
+		domains[p.domain]++
+		pages = append(pages, p)
+		return nil
+	})
+	if err != nil {
+		return vanity.Config{}, nil, err
+	}
+
+	// Pages whose import prefix is not a prefix of their own path appear to
+	// be on a different domain, so the domain is the one most pages are on.
+	for d, n := range domains {
+		if n > domains[c.Domain] || n == domains[c.Domain] && d < c.Domain {
+			c.Domain = d
+		}
+	}
+
+	repos := map[string]*importedRepository{}
+	for _, p := range pages {
+		if p.domain != c.Domain {
+			conflicts = append(conflicts, fmt.Sprintf("%s: go-import prefix %s is not a prefix of %s", p.name, p.goImport[0], path.Join(c.Domain, p.pkg)))
+			continue
+		}
+		r, ok := repos[p.root]
+		if !ok {
+			repos[p.root] = &importedRepository{goImport: p.goImport, goSource: p.goSource, pages: []string{p.pkg}}
+			continue
+		}
+		if !equalFields(r.goImport, p.goImport) || !equalFields(r.goSource, p.goSource) {
+			conflicts = append(conflicts, fmt.Sprintf("%s: meta tags for %s conflict with the tags in the page for %s", p.name, p.goImport[0], path.Join(c.Domain, r.pages[0])))
+			continue
+		}
+		r.pages = append(r.pages, p.pkg)
+	}
+
+	roots := make([]string, 0, len(repos))
+	for root := range repos {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	for _, root := range roots {
+		r := repos[root]
+		repo := vanity.Repository{
+			Prefix: root,
+			Type:   r.goImport[1],
+			URL:    r.goImport[2],
+		}
+		if len(r.goImport) == 4 {
+			repo.Subdir = r.goImport[3]
+		}
+		if r.goSource[1] != "_" {
+			repo.SourceURLs.Home = r.goSource[1]
+		}
+		if r.goSource[2] != "_" {
+			repo.SourceURLs.Dir = r.goSource[2]
+		}
+		if r.goSource[3] != "_" {
+			repo.SourceURLs.File = r.goSource[3]
+		}
+		sort.Strings(r.pages)
+		for _, pkg := range r.pages {
+			if pkg == root {
+				continue
+			}
+			sub := pkg
+			if root != "" {
+				sub = strings.TrimPrefix(pkg, root+"/")
+			}
+			repo.Subs = append(repo.Subs, vanity.Sub{Name: sub})
+		}
+		c.Repositories = append(c.Repositories, minimalRepository(c.Domain, repo, r.goImport, r.goSource))
+	}
+
+	return c, conflicts, nil
+}
+
+// minimalRepository returns the repository with the fewest fields set that
+// generates the same go-import and go-source meta tags as r, leaving out the
+// type and source URLs when vangen infers the same values from the URL.
+func minimalRepository(domain string, r vanity.Repository, goImport, goSource []string) vanity.Repository {
+	inferred := r
+	inferred.Type = ""
+	inferred.SourceURLs = vanity.SourceURLs{}
+	withBranch := inferred
+	withBranch.Branch = "main"
+	withType := inferred
+	withType.Type = r.Type
+
+	for _, candidate := range []vanity.Repository{inferred, withBranch, withType} {
+		c := vanity.Config{Domain: domain, Repositories: []vanity.Repository{candidate}}
+		candidateImport, candidateSource, err := generatedMetaTags(c, candidate.Prefix)
+		if err == nil && equalFields(candidateImport, goImport) && equalFields(candidateSource, goSource) {
+			return candidate
+		}
+	}
+	return r
+}
+
+// generatedMetaTags returns the fields of the go-import and go-source meta
+// tags of the page vangen generates for pkg.
+func generatedMetaTags(c vanity.Config, pkg string) (goImport, goSource []string, err error) {
+	var buf bytes.Buffer
+	err = vanity.NewGenerator(c).WritePackage(&buf, pkg)
+	if err != nil {
+		return nil, nil, err
+	}
+	imports, sources, err := parseMetaTags(&buf)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(imports) != 1 || len(sources) != 1 {
+		return nil, nil, fmt.Errorf("page for %s has %d go-import and %d go-source meta tags", pkg, len(imports), len(sources))
+	}
+	return imports[0], sources[0], nil
+}
+
+// splitImportPrefix splits the import prefix of the go-import meta tag in the
+// page for pkg into the domain and the prefix of the repository below the
+// domain, which is pkg or the closest of its parents that the import prefix
+// ends with. If none match the whole import prefix is the domain.
+func splitImportPrefix(importPrefix, pkg string) (domain, root string) {
+	for root = pkg; root != "" && root != "."; root = path.Dir(root) {
+		domain = strings.TrimSuffix(importPrefix, "/"+root)
+		if domain != importPrefix && domain != "" {
+			return domain, root
+		}
+	}
+	return importPrefix, ""
+}
+
+// parseMetaTags returns the fields of the go-import and go-source meta tags in
+// the head of the HTML page read from r. The page is parsed the same way the
+// go command parses it.
+func parseMetaTags(r io.Reader) (imports, sources [][]string, err error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "utf-8", "ascii":
+			return input, nil
+		default:
+			return nil, fmt.Errorf("can't decode XML document using charset %q", charset)
+		}
+	}
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			return imports, sources, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		if e, ok := t.(xml.StartElement); ok && strings.EqualFold(e.Name.Local, "body") {
+			return imports, sources, nil
+		}
+		if e, ok := t.(xml.EndElement); ok && strings.EqualFold(e.Name.Local, "head") {
+			return imports, sources, nil
+		}
+		e, ok := t.(xml.StartElement)
+		if !ok || !strings.EqualFold(e.Name.Local, "meta") {
+			continue
+		}
+		var name, content string
+		for _, a := range e.Attr {
+			switch strings.ToLower(a.Name.Local) {
+			case "name":
+				name = a.Value
+			case "content":
+				content = a.Value
+			}
+		}
+		fields := strings.Fields(content)
+		switch name {
+		case "go-import":
+			if len(fields) == 3 || len(fields) == 4 {
+				imports = append(imports, fields)
+			}
+		case "go-source":
+			if len(fields) == 4 {
+				sources = append(sources, fields)
+			}
+		}
+	}
+}
+
+func equalFields(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
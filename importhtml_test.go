@@ -0,0 +1,113 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"4d63.com/vangen/vanity"
+)
+
+func TestImportHTMLRoundTrip(t *testing.T) {
+	c := vanity.Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []vanity.Repository{
+			{
+				Prefix: "pkg1",
+				Subs:   []vanity.Sub{{Name: "sub1"}, {Name: "sub2/subsub1"}},
+				URL:    "https://github.com/example/go-pkg1",
+			},
+			{
+				Prefix: "pkg1/nested",
+				URL:    "https://github.com/example/go-pkg1-nested",
+				Branch: "main",
+			},
+			{
+				Prefix: "pkg2",
+				Type:   "hg",
+				URL:    "https://example.com/hg/go-pkg2",
+				SourceURLs: vanity.SourceURLs{
+					Home: "https://example.com/hg/go-pkg2",
+					Dir:  "https://example.com/hg/go-pkg2/browse{/dir}",
+					File: "https://example.com/hg/go-pkg2/browse{/dir}/{file}#L{line}",
+				},
+			},
+			{
+				Prefix: "pkg3",
+				Type:   "git",
+				URL:    "https://example.com/git/monorepo",
+				Subdir: "go/pkg3",
+			},
+		},
+	}
+	files, err := vanity.NewGenerator(c).FS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, conflicts, err := importHTML(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) > 0 {
+		t.Errorf("Got conflicts %q, want none", conflicts)
+	}
+	if !reflect.DeepEqual(imported, c) {
+		t.Errorf("Got config:\n%#v\nwant:\n%#v", imported, c)
+	}
+}
+
+func TestImportHTMLConflicts(t *testing.T) {
+	page := func(goImport, goSource string) *fstest.MapFile {
+		html := `<!DOCTYPE html>
+<html>
+<head>
+<meta name="go-import" content="` + goImport + `">
+`
+		if goSource != "" {
+			html += `<meta name="go-source" content="` + goSource + `">
+`
+		}
+		html += `</head>
+<body>
+<meta name="go-import" content="ignored git https://example.com/ignored">
+</body>
+</html>`
+		return &fstest.MapFile{Data: []byte(html)}
+	}
+	files := fstest.MapFS{
+		"pkg1/index.html":      page("example.com/pkg1 git https://example.com/go-pkg1", ""),
+		"pkg1/sub1/index.html": page("example.com/pkg1 git https://example.com/go-pkg1", ""),
+		"pkg1/sub2/index.html": page("example.com/pkg1 git https://example.com/go-pkg1-fork", ""),
+		"pkg2.html":            page("example.com/pkg2 git https://example.com/go-pkg2", "example.com/pkg2 _ _ _"),
+		"pkg3/index.html":      page("example.com/pkg3 git https://example.com/go-pkg3", "example.com/other _ _ _"),
+		"pkg4/index.html":      page("other.example.com/pkg4 git https://example.com/go-pkg4", ""),
+		"CNAME":                &fstest.MapFile{Data: []byte("example.com\n")},
+	}
+
+	c, conflicts, err := importHTML(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedConflicts := []string{
+		"pkg3/index.html: go-source prefix example.com/other is not the go-import prefix example.com/pkg3",
+		"pkg1/sub2/index.html: meta tags for example.com/pkg1 conflict with the tags in the page for example.com/pkg1",
+		"pkg4/index.html: go-import prefix other.example.com/pkg4 is not a prefix of example.com/pkg4",
+	}
+	if !reflect.DeepEqual(conflicts, expectedConflicts) {
+		t.Errorf("Got conflicts:\n%q\nwant:\n%q", conflicts, expectedConflicts)
+	}
+
+	expectedConfig := vanity.Config{
+		Domain: "example.com",
+		Repositories: []vanity.Repository{
+			{Prefix: "pkg1", Type: "git", URL: "https://example.com/go-pkg1", Subs: []vanity.Sub{{Name: "sub1"}}},
+			{Prefix: "pkg2", Type: "git", URL: "https://example.com/go-pkg2"},
+		},
+	}
+	if !reflect.DeepEqual(c, expectedConfig) {
+		t.Errorf("Got config:\n%#v\nwant:\n%#v", c, expectedConfig)
+	}
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"4d63.com/vangen/vanity"
 )
 
 var version = "<not set>"
@@ -18,23 +22,53 @@ func main() {
 }
 
 func run() error {
-	printHelp := flag.Bool("help", false, "print this help list")
-	printVersion := flag.Bool("version", false, "print program version")
-	verbose := flag.Bool("verbose", false, "print verbose output when run")
-	filename := flag.String("config", "vangen.json", "vangen json configuration `filename`")
-	outputDir := flag.String("out", "vangen/", "output `directory` that static files will be written to")
-	noOverwrite := flag.Bool("no-overwrite", false, "If an output file already exists, stops with a non-zero return code")
-	flag.Usage = func() {
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "serve":
+			return runServe(args[1:])
+		case "check":
+			return runCheck(args[1:])
+		case "verify":
+			return runVerify(args[1:])
+		case "import-html":
+			return runImportHTML(args[1:])
+		case "convert":
+			return runConvert(args[1:])
+		}
+	}
+	return runGenerate(args)
+}
+
+func runGenerate(args []string) error {
+	flags := flag.NewFlagSet("vangen", flag.ExitOnError)
+	printHelp := flags.Bool("help", false, "print this help list")
+	printVersion := flags.Bool("version", false, "print program version")
+	verbose := flags.Bool("verbose", false, "print verbose output when run")
+	filename := flags.String("config", "vangen.json", "vangen configuration `filename`")
+	configFormat := flags.String("config-format", "", configFormatUsage)
+	outputDir := flags.String("out", "vangen/", "output `directory` that static files will be written to")
+	noOverwrite := flags.Bool("no-overwrite", false, "If an output file already exists, stops with a non-zero return code")
+	dryRun := flags.Bool("dry-run", false, "print the files that would be created, overwritten or removed without writing anything")
+	discover := flags.Bool("discover", false, discoverUsage)
+	templates := flags.String("templates", "", templatesUsage)
+	assets := flags.String("assets", "", assetsUsage)
+	flags.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Vangen is a tool for generating static HTML for hosting Go repositories at a vanity import path.\n\n")
 		fmt.Fprintf(os.Stderr, "Usage:\n\n")
-		fmt.Fprintf(os.Stderr, "  vangen [-config=vangen.json] [-out=vangen/]\n\n")
+		fmt.Fprintf(os.Stderr, "  vangen [-config=vangen.json] [-out=vangen/]\n")
+		fmt.Fprintf(os.Stderr, "  vangen serve [-config=vangen.json] [-addr=:8080]\n")
+		fmt.Fprintf(os.Stderr, "  vangen check [-config=vangen.json] [-out=vangen/]\n")
+		fmt.Fprintf(os.Stderr, "  vangen verify [-config=vangen.json] [-clones=directory]\n")
+		fmt.Fprintf(os.Stderr, "  vangen import-html [-config=vangen.json] directory\n")
+		fmt.Fprintf(os.Stderr, "  vangen convert [-from=format] [-to=format] input output\n\n")
 		fmt.Fprintf(os.Stderr, "Flags:\n\n")
-		flag.PrintDefaults()
+		flags.PrintDefaults()
 	}
-	flag.Parse()
+	flags.Parse(args)
 
 	if *printHelp {
-		flag.Usage()
+		flags.Usage()
 		return nil
 	}
 
@@ -43,91 +77,180 @@ func run() error {
 		return nil
 	}
 
-	cf, err := os.Open(*filename)
+	g, err := loadGenerator(*filename, *configFormat, *discover, *templates, *assets)
 	if err != nil {
 		return err
 	}
 
-	c, err := parseConfig(cf)
+	files, err := g.FS()
 	if err != nil {
 		return err
 	}
 
+	p, err := planOutput(files, *outputDir, *noOverwrite)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		p.print(os.Stdout, *outputDir)
+		return nil
+	}
+
 	err = os.MkdirAll(*outputDir, os.ModePerm)
 	if err != nil {
 		return fmt.Errorf("making dir %s: %w", *outputDir, err)
 	}
 
-	if c.Index {
-		pathOut := filepath.Join(*outputDir, "index.html")
-		f, err := os.Create(pathOut)
-		if err != nil {
-			return fmt.Errorf("writing file %s: %w", pathOut, err)
-		}
-		defer func() {
-			err := f.Close()
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "closing file %s: %v", pathOut, err)
-			}
-		}()
+	return p.apply(*outputDir, *verbose)
+}
 
-		if *verbose {
-			fmt.Fprintf(os.Stderr, "Writing %s\n", pathOut)
-		}
-		err = generate_index(f, c.Domain, c.Repositories)
-		if err != nil {
-			return fmt.Errorf("generating index: %w", err)
-		}
+// writeFile atomically replaces the file at name with data, by writing it to
+// a temporary file in the same directory and renaming it into place.
+func writeFile(name string, data []byte) error {
+	tmp, err := stageFile(name, data)
+	if err != nil {
+		return err
+	}
+	err = os.Rename(tmp, name)
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// stageFile writes data to a new temporary file in the same directory as
+// name, flushes it to disk, and returns its path. The temporary file can be
+// renamed to name without the contents at name ever being partially written.
+func stageFile(name string, data []byte) (string, error) {
+	f, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp*")
+	if err != nil {
+		return "", err
+	}
+	tmp := f.Name()
 
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(0o644)
+	}
+	if err == nil {
 		err = f.Sync()
-		if err != nil {
-			return fmt.Errorf("flushing file %s: %w", pathOut, err)
-		}
 	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return tmp, nil
+}
 
-	for _, r := range c.Repositories {
-		for _, p := range r.Packages() {
-			dirOut := filepath.Join(*outputDir, p)
-			err = os.MkdirAll(dirOut, os.ModePerm)
-			if err != nil {
-				return fmt.Errorf("making dir %s: %v", dirOut, err)
+// loadConfig reads, parses and validates the config file at filename, and
+// reads any local clones it refers to. If format is empty it is chosen by the
+// filename extension. If discover is true subs are discovered for every
+// repository with a clone.
+func loadConfig(filename, format string, discover bool) (vanity.Config, error) {
+	c, err := parseConfigFile(filename, format)
+	if err != nil {
+		return vanity.Config{}, err
+	}
+
+	if discover {
+		for i := range c.Repositories {
+			if c.Repositories[i].Clone != "" {
+				c.Repositories[i].Discover = true
 			}
+		}
+	}
+	err = c.ReadClones(filepath.Dir(filename))
+	if err != nil {
+		return vanity.Config{}, err
+	}
 
-			pathOut := filepath.Join(dirOut, "index.html")
-			if *noOverwrite {
-				if _, err := os.Stat(pathOut); !os.IsNotExist(err) {
-					if err == nil {
-						return fmt.Errorf("cannot overwrite output file %s: %w", pathOut, err)
-					}
+	return c, nil
+}
 
-					return fmt.Errorf("checking file %s: %w", pathOut, err)
-				}
-			}
-			f, err := os.Create(pathOut)
-			if err != nil {
-				return fmt.Errorf("writing file %s: %v", pathOut, err)
-			}
-			defer func() {
-				err := f.Close()
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "closing file %s: %v", pathOut, err)
-				}
-			}()
-
-			if *verbose {
-				fmt.Fprintf(os.Stderr, "Writing %s\n", pathOut)
-			}
-			err = generate_package(f, c.Domain, c.DocsDomain, p, r)
-			if err != nil {
-				return fmt.Errorf("generating package %s: %w", p, err)
-			}
+// parseConfigFile reads, parses and validates the config file at filename,
+// without reading the local clones it refers to. If format is empty it is
+// chosen by the filename extension.
+func parseConfigFile(filename, format string) (vanity.Config, error) {
+	if format == "" {
+		format = vanity.FormatForFilename(filename)
+	}
 
-			err = f.Sync()
-			if err != nil {
-				return fmt.Errorf("flushing file %s: %w", pathOut, err)
+	f, err := os.Open(filename)
+	if err != nil {
+		return vanity.Config{}, err
+	}
+	defer f.Close()
+
+	c, err := vanity.ParseConfigFormat(f, format)
+	var validationErr *vanity.ValidationError
+	if errors.As(err, &validationErr) {
+		problems := make([]string, len(validationErr.Problems))
+		for i, p := range validationErr.Problems {
+			if p.Line > 0 {
+				problems[i] = fmt.Sprintf("%s:%s", filename, p)
+			} else {
+				problems[i] = fmt.Sprintf("%s: %s", filename, p)
 			}
 		}
+		return vanity.Config{}, fmt.Errorf("invalid config:\n%s", strings.Join(problems, "\n"))
+	} else if err != nil {
+		return vanity.Config{}, fmt.Errorf("parsing config %s: %w", filename, err)
 	}
+	return c, nil
+}
 
-	return nil
+const (
+	configFormatUsage = "configuration `format`, one of json, yaml or toml, chosen by the config filename extension if not set"
+	discoverUsage     = "add a sub for every package found in the clone of each repository with a clone, as if discover were set on it"
+	templatesUsage    = "`directory` of templates replacing the built-in pages or their blocks, in place of the templates directory in the config"
+	assetsUsage       = "`directory` of assets to write alongside the pages, in place of the assets directory in the config"
+)
+
+// loadGenerator loads the config at filename and returns a generator for it.
+// Pages are rendered with the templates in templatesDir, and written with
+// the assets in assetsDir. If either is empty the directory in the config is
+// used, which is relative to the directory containing the config file.
+func loadGenerator(filename, format string, discover bool, templatesDir, assetsDir string) (*vanity.Generator, error) {
+	c, err := loadConfig(filename, format, discover)
+	if err != nil {
+		return nil, err
+	}
+	g := vanity.NewGenerator(c)
+
+	if assetsDir == "" {
+		assetsDir = configPath(filename, c.Assets)
+	}
+	if assetsDir != "" {
+		g.Assets, err = vanity.ReadAssets(os.DirFS(assetsDir))
+		if err != nil {
+			return nil, fmt.Errorf("reading assets in %s: %w", assetsDir, err)
+		}
+	}
+
+	if templatesDir == "" {
+		templatesDir = configPath(filename, c.Templates)
+	}
+	if templatesDir != "" {
+		g.Templates, err = vanity.ParseTemplates(os.DirFS(templatesDir), c.Theme, g.Assets)
+		if err != nil {
+			return nil, fmt.Errorf("reading templates in %s: %w", templatesDir, err)
+		}
+	}
+
+	return g, nil
+}
+
+// configPath returns the path p from the config file at filename, which is
+// relative to the directory containing the config file if it is not
+// absolute.
+func configPath(filename, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(filepath.Dir(filename), p)
 }
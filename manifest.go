@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// manifestName is the name of the file in the output directory that lists
+// the files vangen generated, so that files no longer generated can be
+// removed on a later run.
+const manifestName = ".vangen-manifest"
+
+const manifestHeader = "# Files generated by vangen. Do not edit.\n"
+
+// readManifest returns the slash separated paths listed in the manifest in
+// outputDir. If there is no manifest it returns nil.
+func readManifest(outputDir string) ([]string, error) {
+	f, err := os.Open(filepath.Join(outputDir, manifestName))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	names := []string{}
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsLocal(filepath.FromSlash(line)) {
+			return nil, fmt.Errorf("manifest lists path outside output directory: %s", line)
+		}
+		names = append(names, line)
+	}
+	return names, s.Err()
+}
+
+// writeManifest writes a manifest to outputDir listing names.
+func writeManifest(outputDir string, names []string) error {
+	sorted := append([]string(nil), names...)
+	sort.Strings(sorted)
+
+	var sb strings.Builder
+	sb.WriteString(manifestHeader)
+	for _, n := range sorted {
+		sb.WriteString(n)
+		sb.WriteString("\n")
+	}
+	return writeFile(filepath.Join(outputDir, manifestName), []byte(sb.String()))
+}
+
+// staleFiles returns the names in previous that are not in current.
+func staleFiles(previous, current []string) []string {
+	keep := map[string]bool{}
+	for _, n := range current {
+		keep[n] = true
+	}
+	var stale []string
+	for _, n := range previous {
+		if !keep[n] {
+			stale = append(stale, n)
+		}
+	}
+	return stale
+}
+
+// removeFile removes the file name in outputDir, and then any parent
+// directories that are left empty, stopping at outputDir. A file that has
+// already been removed is not an error.
+func removeFile(outputDir, name string) error {
+	p := filepath.Join(outputDir, filepath.FromSlash(name))
+	err := os.Remove(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for dir := filepath.Dir(p); dir != filepath.Clean(outputDir); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(entries) > 0 {
+			break
+		}
+		err = os.Remove(dir)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"pkg2/index.html", "index.html", "pkg1/sub/index.html"}
+
+	err := writeManifest(dir, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := readManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"index.html", "pkg1/sub/index.html", "pkg2/index.html"}
+	if !reflect.DeepEqual(read, expected) {
+		t.Errorf("Got names %#v, want %#v", read, expected)
+	}
+}
+
+func TestReadManifestMissing(t *testing.T) {
+	names, err := readManifest(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Got names %#v, want none", names)
+	}
+}
+
+func TestReadManifestOutsideOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, manifestName), []byte("index.html\n../index.html\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = readManifest(dir)
+	if err == nil {
+		t.Errorf("Got no error, want error")
+	}
+}
+
+func TestGeneratePrunesRemovedPackages(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "vangen.json")
+	outputDir := filepath.Join(dir, "vangen")
+	generate := func(config string) {
+		t.Helper()
+		err := os.WriteFile(configFile, []byte(config), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = runGenerate([]string{"-config", configFile, "-out", outputDir})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	exists := func(name string) bool {
+		t.Helper()
+		_, err := os.Stat(filepath.Join(outputDir, filepath.FromSlash(name)))
+		if errors.Is(err, fs.ErrNotExist) {
+			return false
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return true
+	}
+
+	generate(`{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "subs": ["sub1", "sub2/subsub1"]},
+    {"prefix": "pkg2", "url": "https://github.com/example/go-pkg2"}
+  ]
+}`)
+
+	err := os.WriteFile(filepath.Join(outputDir, "CNAME"), []byte("example.com\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(outputDir, "pkg2", "logo.png"), nil, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	generate(`{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "subs": ["sub1"]}
+  ]
+}`)
+
+	expected := map[string]bool{
+		"CNAME":                        true,
+		"pkg1/index.html":              true,
+		"pkg1/sub1/index.html":         true,
+		"pkg1/sub2/subsub1/index.html": false,
+		"pkg1/sub2":                    false,
+		"pkg2/index.html":              false,
+		"pkg2/logo.png":                true,
+	}
+	for name, want := range expected {
+		if got := exists(name); got != want {
+			t.Errorf("Got %s exists %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// fileWrite is a generated file that will be written to the output directory.
+type fileWrite struct {
+	// name is the slash separated path relative to the output directory.
+	name string
+	data []byte
+	// exists is true if a file is already at the path and will be
+	// overwritten.
+	exists bool
+	// changed is true if the file already at the path has different
+	// contents.
+	changed bool
+}
+
+// outputPlan is the set of changes that generating files makes to an output
+// directory.
+type outputPlan struct {
+	writes  []fileWrite
+	removes []string
+}
+
+// planOutput compares the generated files with outputDir and returns the
+// files to write and the previously generated files to remove. If
+// noOverwrite is true it is an error for any file other than the index at
+// the root of outputDir to already exist. The index lists every repository,
+// so it is always rewritten.
+func planOutput(files fs.FS, outputDir string, noOverwrite bool) (outputPlan, error) {
+	var p outputPlan
+	written := []string{}
+	err := fs.WalkDir(files, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(files, name)
+		if err != nil {
+			return err
+		}
+
+		w := fileWrite{name: name, data: data}
+		pathOut := filepath.Join(outputDir, filepath.FromSlash(name))
+		existing, err := os.ReadFile(pathOut)
+		if err == nil {
+			if noOverwrite && name != "index.html" {
+				return fmt.Errorf("cannot overwrite output file %s", pathOut)
+			}
+			w.exists = true
+			w.changed = !bytes.Equal(existing, data)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("checking file %s: %w", pathOut, err)
+		}
+
+		p.writes = append(p.writes, w)
+		written = append(written, name)
+		return nil
+	})
+	if err != nil {
+		return outputPlan{}, err
+	}
+
+	previous, err := readManifest(outputDir)
+	if err != nil {
+		return outputPlan{}, fmt.Errorf("reading manifest: %w", err)
+	}
+	p.removes = staleFiles(previous, written)
+
+	return p, nil
+}
+
+// print writes a line to w for each file that will be created, overwritten
+// or removed.
+func (p outputPlan) print(w io.Writer, outputDir string) {
+	for _, f := range p.writes {
+		pathOut := filepath.Join(outputDir, filepath.FromSlash(f.name))
+		switch {
+		case !f.exists:
+			fmt.Fprintf(w, "create %s\n", pathOut)
+		case f.changed:
+			fmt.Fprintf(w, "overwrite %s (changed)\n", pathOut)
+		default:
+			fmt.Fprintf(w, "overwrite %s (unchanged)\n", pathOut)
+		}
+	}
+	for _, name := range p.removes {
+		fmt.Fprintf(w, "remove %s\n", filepath.Join(outputDir, filepath.FromSlash(name)))
+	}
+}
+
+// apply writes and removes the files in outputDir, and records the written
+// files in the manifest. Every file is written to a temporary file beside its
+// destination before any are renamed into place, so that each file is
+// replaced whole and writing any file failing leaves the existing files as
+// they were. If renaming a file into place fails, the files renamed before it
+// stay in place. After a failure, temporary files and the directories made
+// for them that are left empty are removed.
+func (p outputPlan) apply(outputDir string, verbose bool) error {
+	staged := make([]string, 0, len(p.writes))
+	var dirs []string
+	removeStaged := func() {
+		for _, tmp := range staged {
+			os.Remove(tmp)
+		}
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i])
+		}
+	}
+	for _, f := range p.writes {
+		pathOut := filepath.Join(outputDir, filepath.FromSlash(f.name))
+		made, err := makeDirs(filepath.Dir(pathOut))
+		dirs = append(dirs, made...)
+		if err != nil {
+			removeStaged()
+			return fmt.Errorf("making dir %s: %w", filepath.Dir(pathOut), err)
+		}
+
+		tmp, err := stageFile(pathOut, f.data)
+		if err != nil {
+			removeStaged()
+			return fmt.Errorf("writing file %s: %w", pathOut, err)
+		}
+		staged = append(staged, tmp)
+	}
+
+	written := []string{}
+	for i, f := range p.writes {
+		pathOut := filepath.Join(outputDir, filepath.FromSlash(f.name))
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Writing %s\n", pathOut)
+		}
+		err := os.Rename(staged[i], pathOut)
+		if err != nil {
+			removeStaged()
+			return fmt.Errorf("writing file %s: %w", pathOut, err)
+		}
+		written = append(written, f.name)
+	}
+
+	for _, name := range p.removes {
+		pathOut := filepath.Join(outputDir, filepath.FromSlash(name))
+		if verbose {
+			fmt.Fprintf(os.Stderr, "Removing %s\n", pathOut)
+		}
+		err := removeFile(outputDir, name)
+		if err != nil {
+			return fmt.Errorf("removing file %s: %w", pathOut, err)
+		}
+	}
+
+	err := writeManifest(outputDir, written)
+	if err != nil {
+		return fmt.Errorf("writing manifest: %w", err)
+	}
+
+	return nil
+}
+
+// makeDirs makes dir and any parents that do not exist, and returns the
+// directories it made, parents first.
+func makeDirs(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		_, err := os.Stat(d)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	made := []string{}
+	for i := len(missing) - 1; i >= 0; i-- {
+		err := os.Mkdir(missing[i], os.ModePerm)
+		if err != nil && !errors.Is(err, fs.ErrExist) {
+			return made, err
+		}
+		if err == nil {
+			made = append(made, missing[i])
+		}
+	}
+	return made, nil
+}
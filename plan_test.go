@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestPlanOutput(t *testing.T) {
+	files := fstest.MapFS{
+		"index.html":      {Data: []byte("index\n")},
+		"pkg1/index.html": {Data: []byte("pkg1\n")},
+		"pkg2/index.html": {Data: []byte("pkg2\n")},
+	}
+
+	outputDir := t.TempDir()
+	writeFiles(t, outputDir, map[string]string{
+		"index.html":      "index\n",
+		"pkg1/index.html": "pkg1 stale\n",
+		"pkg3/index.html": "pkg3\n",
+		"CNAME":           "example.com\n",
+	})
+	err := writeManifest(outputDir, []string{"index.html", "pkg1/index.html", "pkg3/index.html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := planOutput(files, outputDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	p.print(&out, "OUT")
+	expectedOut := strings.ReplaceAll(`overwrite OUT/index.html (unchanged)
+overwrite OUT/pkg1/index.html (changed)
+create OUT/pkg2/index.html
+remove OUT/pkg3/index.html
+`, "/", string(filepath.Separator))
+	if g, w := out.String(), expectedOut; g != w {
+		t.Errorf("Got plan:\n%s\nwant:\n%s", g, w)
+	}
+
+	_, err = planOutput(files, outputDir, true)
+	if g, w := err, "cannot overwrite output file "+filepath.Join(outputDir, "pkg1", "index.html"); g == nil || g.Error() != w {
+		t.Errorf("Got err %v planning with no overwrite, want %s", g, w)
+	}
+
+	// The index is always rewritten.
+	_, err = planOutput(fstest.MapFS{"index.html": {Data: []byte("index\n")}}, outputDir, true)
+	if err != nil {
+		t.Errorf("Got err %v planning the index with no overwrite, want nil", err)
+	}
+}
+
+func TestPlanApplyFailureLeavesOutputUnchanged(t *testing.T) {
+	outputDir := t.TempDir()
+	// A file where a directory needs to be causes writing pkg2 to fail after
+	// pkg1 has been written to a temporary file.
+	writeFiles(t, outputDir, map[string]string{
+		"pkg1/index.html": "pkg1 old\n",
+		"pkg2":            "",
+	})
+
+	p := outputPlan{
+		writes: []fileWrite{
+			{name: "pkg1/index.html", data: []byte("pkg1 new\n"), exists: true, changed: true},
+			{name: "pkg0/sub/index.html", data: []byte("pkg0 sub new\n")},
+			{name: "pkg2/index.html", data: []byte("pkg2 new\n")},
+		},
+	}
+	err := p.apply(outputDir, false)
+	if err == nil {
+		t.Fatal("Got no error, want error")
+	}
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "pkg1", "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(data), "pkg1 old\n"; g != w {
+		t.Errorf("Got pkg1/index.html %q, want %q", g, w)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(outputDir, "pkg1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(entries), 1; g != w {
+		t.Errorf("Got %d files in pkg1, want %d", g, w)
+	}
+
+	_, err = os.Stat(filepath.Join(outputDir, "pkg0"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Got pkg0 stat error %v, want %v", err, fs.ErrNotExist)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "index.html")
+
+	for _, content := range []string{"one\n", "two\n"} {
+		err := writeFile(name, []byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g, w := string(data), content; g != w {
+			t.Errorf("Got %q, want %q", g, w)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := len(entries), 1; g != w {
+		t.Errorf("Got %d files, want %d", g, w)
+	}
+}
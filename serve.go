@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"4d63.com/vangen/vanity"
+)
+
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("vangen serve", flag.ExitOnError)
+	verbose := flags.Bool("verbose", false, "log each request served")
+	filename := flags.String("config", "vangen.json", "vangen configuration `filename`")
+	configFormat := flags.String("config-format", "", configFormatUsage)
+	addr := flags.String("addr", ":8080", "`address` to listen on for HTTP requests")
+	discover := flags.Bool("discover", false, discoverUsage)
+	templates := flags.String("templates", "", templatesUsage)
+	assets := flags.String("assets", "", assetsUsage)
+	reloadInterval := flags.Duration("reload-interval", 2*time.Second, "`interval` at which the config file is checked for changes, 0 disables reloading")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Serve responds to HTTP requests with the pages vangen would generate, without writing any files.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "  vangen serve [-config=vangen.json] [-addr=:8080]\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	g, err := loadGenerator(*filename, *configFormat, *discover, *templates, *assets)
+	if err != nil {
+		return err
+	}
+
+	handler := vanity.NewHandler(g)
+	if *reloadInterval > 0 {
+		go watchFile(*filename, *reloadInterval, func() {
+			err := reload(handler, *filename, *configFormat, *discover, *templates, *assets)
+			if err != nil {
+				log.Printf("reloading config, continuing to serve previous config: %v", err)
+				return
+			}
+			log.Printf("reloaded config %s", *filename)
+		})
+	}
+
+	var h http.Handler = handler
+	if *verbose {
+		h = logRequests(h)
+	}
+
+	fmt.Fprintf(os.Stderr, "Serving %s on %s\n", g.Config.Domain, *addr)
+	return http.ListenAndServe(*addr, h)
+}
+
+// reload loads the config from filename, with the templates and assets, and
+// swaps them in for those being served by h. If any cannot be loaded the
+// config, templates and assets being served are kept.
+func reload(h *vanity.Handler, filename, format string, discover bool, templatesDir, assetsDir string) error {
+	g, err := loadGenerator(filename, format, discover, templatesDir, assetsDir)
+	if err != nil {
+		return err
+	}
+	h.SetGenerator(g)
+	return nil
+}
+
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log.Printf("%s %s", r.Method, r.URL)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// watchFile polls filename every interval and calls changed whenever its
+// modification time or size differs from when it was last checked. It never
+// returns.
+func watchFile(filename string, interval time.Duration, changed func()) {
+	last, _ := os.Stat(filename)
+	for range time.Tick(interval) {
+		fi, err := os.Stat(filename)
+		if err != nil {
+			log.Printf("watching config: %v", err)
+			continue
+		}
+		if last != nil && fi.ModTime().Equal(last.ModTime()) && fi.Size() == last.Size() {
+			continue
+		}
+		last = fi
+		changed()
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"4d63.com/vangen/vanity"
+)
+
+func TestServeReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "vangen.json")
+	writeConfig := func(content string) {
+		err := os.WriteFile(filename, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	status := func(h *vanity.Handler, target string) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", target, nil))
+		return rec.Code
+	}
+
+	writeConfig(`{"domain": "example.com", "repositories": [{"prefix": "pkg1", "url": "https://github.com/example/go-pkg1"}]}`)
+	c, err := loadConfig(filename, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := vanity.NewHandler(vanity.NewGenerator(c))
+
+	writeConfig(`{"domain": "example.com", "repositories": [{"prefix": "pkg2", "url": "https://github.com/example/go-pkg2"}]}`)
+	err = reload(h, filename, "", false, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := status(h, "/pkg1"), http.StatusNotFound; g != w {
+		t.Errorf("Got status for pkg1 %d, want %d", g, w)
+	}
+	if g, w := status(h, "/pkg2"), http.StatusOK; g != w {
+		t.Errorf("Got status for pkg2 %d, want %d", g, w)
+	}
+
+	invalidConfigs := []string{
+		`{"domain": "example.com", "repositories": [{"prefix": "pkg3", "url": "https://github.com/example/go-pkg3"}`,
+		`{"repositories": [{"prefix": "pkg3", "url": "https://github.com/example/go-pkg3"}]}`,
+		`{"domain": "example.com", "repositories": [{"prefix": "pkg3", "url": "https://github.com/example/go-pkg3"}, {"prefix": "pkg3", "url": "https://github.com/example/go-pkg3"}]}`,
+	}
+	for _, ic := range invalidConfigs {
+		writeConfig(ic)
+		err = reload(h, filename, "", false, "", "")
+		if err == nil {
+			t.Errorf("Reloading %s got no error, want error", ic)
+		}
+		if g, w := status(h, "/pkg2"), http.StatusOK; g != w {
+			t.Errorf("After reloading %s got status for pkg2 %d, want %d", ic, g, w)
+		}
+		if g, w := status(h, "/pkg3"), http.StatusNotFound; g != w {
+			t.Errorf("After reloading %s got status for pkg3 %d, want %d", ic, g, w)
+		}
+	}
+}
+
+func TestWatchFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "vangen.json")
+	err := os.WriteFile(filename, []byte(`{}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := make(chan struct{}, 1)
+	go watchFile(filename, 10*time.Millisecond, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	err = os.WriteFile(filename, []byte(`{"domain": "example.com"}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Got no change notification, want change notification")
+	}
+}
+
+func TestLoadGeneratorTemplates(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "vangen.json")
+	writeFiles(t, dir, map[string]string{
+		"vangen.json":         `{"domain": "example.com", "index": true, "templates": "site", "repositories": [{"prefix": "pkg1", "url": "https://github.com/example/go-pkg1"}]}`,
+		"site/index.html":     `config {{.Domain}}`,
+		"override/index.html": `flag {{.Domain}}`,
+	})
+
+	testCases := []struct {
+		description   string
+		templatesDir  string
+		expectedIndex string
+	}{
+		{"config relative to config file", "", "config example.com"},
+		{"flag overrides config", filepath.Join(dir, "override"), "flag example.com"},
+	}
+	for _, tc := range testCases {
+		g, err := loadGenerator(filename, "", false, tc.templatesDir, "")
+		if err != nil {
+			t.Fatalf("Test case %q got error %v", tc.description, err)
+		}
+		var index strings.Builder
+		err = g.WriteIndex(&index)
+		if err != nil {
+			t.Fatalf("Test case %q got error %v", tc.description, err)
+		}
+		if g, w := index.String(), tc.expectedIndex; g != w {
+			t.Errorf("Test case %q got index %q, want %q", tc.description, g, w)
+		}
+	}
+
+	_, err := loadGenerator(filename, "", false, filepath.Join(dir, "missing"), "")
+	if err == nil {
+		t.Errorf("Got no error for a missing templates directory, want error")
+	}
+}
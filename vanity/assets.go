@@ -0,0 +1,97 @@
+package vanity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Assets are static files, such as stylesheets, logos and favicons, that are
+// written alongside the pages. Each is written with a hash of its contents
+// in its name, so that it can be served with a long cache lifetime and a
+// changed file is always fetched under a new name.
+type Assets struct {
+	// files are the contents of the assets keyed by their hashed names, and
+	// names the hashed names keyed by the names of the assets.
+	files map[string][]byte
+	names map[string]string
+}
+
+// ReadAssets reads every file in fsys as an asset, other than files and
+// directories whose names start with a dot.
+func ReadAssets(fsys fs.FS) (*Assets, error) {
+	a := &Assets{files: map[string][]byte{}, names: map[string]string{}}
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		hashed := hashedName(name, data)
+		a.files[hashed] = data
+		a.names[name] = hashed
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// hashedName returns name with a hash of data inserted before its extension,
+// such as css/site.0123456789.css for css/site.css.
+func hashedName(name string, data []byte) string {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])[:10]
+	ext := path.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hash + ext
+}
+
+// URL returns the absolute path that the asset name is served at, with the
+// hash of its contents in the file name. It is available to templates as the
+// asset function.
+func (a *Assets) URL(name string) (string, error) {
+	if a != nil {
+		if hashed, ok := a.names[name]; ok {
+			return "/" + hashed, nil
+		}
+	}
+	return "", fmt.Errorf("asset %q not found", name)
+}
+
+// Files returns the hashed names of the assets, sorted.
+func (a *Assets) Files() []string {
+	if a == nil {
+		return nil
+	}
+	files := make([]string, 0, len(a.files))
+	for name := range a.files {
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// file returns the contents of the asset with the hashed name.
+func (a *Assets) file(hashed string) ([]byte, bool) {
+	if a == nil {
+		return nil, false
+	}
+	data, ok := a.files[hashed]
+	return data, ok
+}
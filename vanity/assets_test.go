@@ -0,0 +1,128 @@
+package vanity
+
+import (
+	"io/fs"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadAssets(t *testing.T) {
+	assets, err := ReadAssets(fstest.MapFS{
+		"css/site.css":      {Data: []byte("body { color: red; }\n")},
+		"favicon":           {Data: []byte("PNG")},
+		".DS_Store":         {Data: []byte("ignored")},
+		".git/config":       {Data: []byte("ignored")},
+		"css/.site.css.swp": {Data: []byte("ignored")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedFiles := []string{"css/site.9767e91e9d.css", "favicon.7961208376"}
+	if g, w := assets.Files(), expectedFiles; !reflect.DeepEqual(g, w) {
+		t.Errorf("Got files %q, want %q", g, w)
+	}
+
+	testCases := []struct {
+		name        string
+		expectedURL string
+		expectedErr string
+	}{
+		{name: "css/site.css", expectedURL: "/css/site.9767e91e9d.css"},
+		{name: "favicon", expectedURL: "/favicon.7961208376"},
+		{name: "logo.png", expectedErr: `asset "logo.png" not found`},
+		{name: ".DS_Store", expectedErr: `asset ".DS_Store" not found`},
+	}
+	for _, tc := range testCases {
+		u, err := assets.URL(tc.name)
+		if err != nil {
+			if err.Error() != tc.expectedErr {
+				t.Errorf("Test case %q got err %v, want %q", tc.name, err, tc.expectedErr)
+			}
+		} else if u != tc.expectedURL {
+			t.Errorf("Test case %q got URL %q, want %q", tc.name, u, tc.expectedURL)
+		}
+	}
+}
+
+func TestGeneratorAssets(t *testing.T) {
+	assets, err := ReadAssets(fstest.MapFS{
+		"css/site.css": {Data: []byte("body { color: red; }\n")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := ParseTemplates(fstest.MapFS{
+		"blocks.html": {Data: []byte(`{{define "style"}}<link rel="stylesheet" href="{{asset "css/site.css"}}">
+{{end}}`)},
+	}, "", assets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/go-pkg1"},
+		},
+	})
+	g.Templates = templates
+	g.Assets = assets
+
+	files, err := g.FS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	css, err := fs.ReadFile(files, "css/site.9767e91e9d.css")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(css), "body { color: red; }\n"; g != w {
+		t.Errorf("Got asset %q, want %q", g, w)
+	}
+	pkg, err := fs.ReadFile(files, "pkg1/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := `<link rel="stylesheet" href="/css/site.9767e91e9d.css">`; !strings.Contains(string(pkg), w) {
+		t.Errorf("Got package:\n%s\nwant it to contain %q", pkg, w)
+	}
+
+	rec := httptest.NewRecorder()
+	NewHandler(g).ServeHTTP(rec, httptest.NewRequest("GET", "/css/site.9767e91e9d.css", nil))
+	if g, w := rec.Code, 200; g != w {
+		t.Errorf("Got status %d, want %d", g, w)
+	}
+	if g, w := rec.Header().Get("Content-Type"), "text/css; charset=utf-8"; g != w {
+		t.Errorf("Got Content-Type %q, want %q", g, w)
+	}
+	if g, w := rec.Header().Get("Cache-Control"), "public, max-age=31536000, immutable"; g != w {
+		t.Errorf("Got Cache-Control %q, want %q", g, w)
+	}
+	if g, w := rec.Body.String(), "body { color: red; }\n"; g != w {
+		t.Errorf("Got body %q, want %q", g, w)
+	}
+
+	rec = httptest.NewRecorder()
+	NewHandler(g).ServeHTTP(rec, httptest.NewRequest("GET", "/css/site.css", nil))
+	if g, w := rec.Code, 404; g != w {
+		t.Errorf("Got status for unhashed name %d, want %d", g, w)
+	}
+}
+
+func TestParseTemplatesUnknownAsset(t *testing.T) {
+	templates, err := ParseTemplates(fstest.MapFS{
+		"index.html": {Data: []byte(`<img src="{{asset "logo.png"}}">`)},
+	}, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(Config{Domain: "example.com", Index: true})
+	g.Templates = templates
+	_, err = g.FS()
+	if err == nil || !strings.Contains(err.Error(), `asset "logo.png" not found`) {
+		t.Errorf("Got err %v, want asset not found", err)
+	}
+}
@@ -0,0 +1,147 @@
+package vanity
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReadClones reads information about repositories from the local clones
+// configured with Clone. Clone paths that are relative are relative to dir,
+// which is usually the directory containing the config file.
+//
+// Repositories with their Branch set to BranchClone, or without a Branch in a
+// config with its Branch set to BranchClone, have it set to the default
+// branch of the clone. If the clone is not a git clone, or its HEAD is
+// detached, the branch is left unset and the usual default is used.
+// Repositories with Discover set have a sub added for every package found in
+// the clone whose path is a valid package path not already generated by the
+// config, such as by Subs or Versions.
+func (c *Config) ReadClones(dir string) error {
+	packages := map[string]bool{}
+	for _, r := range c.allRepositories() {
+		for _, pkg := range r.Packages() {
+			packages[pkg] = true
+		}
+	}
+	for i := range c.Repositories {
+		r := &c.Repositories[i]
+		if r.Clone == "" {
+			continue
+		}
+		clone := r.Clone
+		if !filepath.IsAbs(clone) {
+			clone = filepath.Join(dir, clone)
+		}
+
+		if r.Branch == BranchClone || r.Branch == "" && c.Branch == BranchClone {
+			branch, err := cloneBranch(clone)
+			if err != nil {
+				return fmt.Errorf("repository %q: reading default branch of clone %s: %w", r.Prefix, clone, err)
+			}
+			r.Branch = branch
+		}
+
+		if r.Discover {
+			root := filepath.Join(clone, filepath.FromSlash(r.Subdir))
+			subs, err := discoverSubs(root)
+			if err != nil {
+				return fmt.Errorf("repository %q: discovering subs in clone %s: %w", r.Prefix, clone, err)
+			}
+			r.Subs = mergeSubs(r.Subs, newSubs(*r, subs, packages))
+		}
+	}
+	return nil
+}
+
+// cloneBranch returns the default branch of the git clone at dir. The default
+// branch is the branch the origin remote's HEAD refers to, or if the clone has
+// no origin HEAD, the branch that is checked out. It returns an empty branch
+// if dir is not a git clone or its HEAD is detached.
+func cloneBranch(dir string) (string, error) {
+	_, err := os.Stat(dir)
+	if err != nil {
+		return "", err
+	}
+	gitDir, err := cloneGitDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	// Worktrees have their own HEAD but share remote refs with the main
+	// clone, which they name in a commondir file.
+	commonDir := gitDir
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(data))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+
+	branch, err := readSymbolicRef(filepath.Join(commonDir, "refs", "remotes", "origin", "HEAD"), "refs/remotes/origin/")
+	if err == nil {
+		return branch, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	branch, err = readSymbolicRef(filepath.Join(gitDir, "HEAD"), "refs/heads/")
+	if errors.Is(err, errNotSymbolicRef) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return branch, nil
+}
+
+// cloneGitDir returns the git directory of the clone at dir, following the
+// .git file that worktrees and submodules have in place of a directory.
+func cloneGitDir(dir string) (string, error) {
+	gitDir := filepath.Join(dir, ".git")
+	fi, err := os.Stat(gitDir)
+	if err != nil {
+		return "", err
+	}
+	if fi.IsDir() {
+		return gitDir, nil
+	}
+
+	data, err := os.ReadFile(gitDir)
+	if err != nil {
+		return "", err
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("%s is not a git directory or gitdir file", gitDir)
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return target, nil
+}
+
+// errNotSymbolicRef is returned by readSymbolicRef for refs that are not
+// symbolic, such as a detached HEAD.
+var errNotSymbolicRef = errors.New("not a symbolic ref")
+
+// readSymbolicRef reads the symbolic ref file at name and returns the name of
+// the ref it points to with prefix removed.
+func readSymbolicRef(name, prefix string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	ref, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "ref: ")
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, errNotSymbolicRef)
+	}
+	branch, ok := strings.CutPrefix(ref, prefix)
+	if !ok {
+		return "", fmt.Errorf("%s refers to %s which is not in %s", name, ref, prefix)
+	}
+	return branch, nil
+}
@@ -0,0 +1,77 @@
+package vanity
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadClonesBranch(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"origin/.git/HEAD":                         "ref: refs/heads/feature\n",
+		"origin/.git/refs/remotes/origin/HEAD":     "ref: refs/remotes/origin/main\n",
+		"local/.git/HEAD":                          "ref: refs/heads/trunk\n",
+		"detached/.git/HEAD":                       "0123456789abcdef0123456789abcdef01234567\n",
+		"hgclone/.hg/requires":                     "store\n",
+		"worktree/.git":                            "gitdir: ../origin/.git/worktrees/worktree\n",
+		"origin/.git/worktrees/worktree/HEAD":      "ref: refs/heads/feature\n",
+		"origin/.git/worktrees/worktree/commondir": "../..\n",
+	})
+
+	testCases := []struct {
+		description    string
+		branch         string
+		r              Repository
+		expectedBranch string
+		expectedErr    bool
+	}{
+		{description: "no clone", branch: BranchClone, r: Repository{Prefix: "pkg"}, expectedBranch: ""},
+		{description: "origin head", r: Repository{Prefix: "pkg", Clone: "origin", Branch: BranchClone}, expectedBranch: "main"},
+		{description: "checked out branch", r: Repository{Prefix: "pkg", Clone: "local", Branch: BranchClone}, expectedBranch: "trunk"},
+		{description: "worktree", r: Repository{Prefix: "pkg", Clone: "worktree", Branch: BranchClone}, expectedBranch: "main"},
+		{description: "absolute path", r: Repository{Prefix: "pkg", Clone: filepath.Join(dir, "local"), Branch: BranchClone}, expectedBranch: "trunk"},
+		{description: "config branch clone", branch: BranchClone, r: Repository{Prefix: "pkg", Clone: "origin"}, expectedBranch: "main"},
+		{description: "not read by default", r: Repository{Prefix: "pkg", Clone: "origin"}, expectedBranch: ""},
+		{description: "config branch set", branch: "main", r: Repository{Prefix: "pkg", Clone: "local"}, expectedBranch: ""},
+		{description: "branch set", branch: BranchClone, r: Repository{Prefix: "pkg", Clone: "origin", Branch: "release"}, expectedBranch: "release"},
+		{description: "detached", r: Repository{Prefix: "pkg", Clone: "detached", Branch: BranchClone}, expectedBranch: ""},
+		{description: "not git", r: Repository{Prefix: "pkg", Clone: "hgclone", Branch: BranchClone}, expectedBranch: ""},
+		{description: "missing", r: Repository{Prefix: "pkg", Clone: "missing", Branch: BranchClone}, expectedErr: true},
+	}
+
+	for _, tc := range testCases {
+		c := Config{Branch: tc.branch, Repositories: []Repository{tc.r}}
+		err := c.ReadClones(dir)
+		if tc.expectedErr {
+			if err == nil {
+				t.Errorf("Test case %q got no error, want error", tc.description)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test case %q got err %v", tc.description, err)
+			continue
+		}
+		if g, w := c.Repositories[0].Branch, tc.expectedBranch; g != w {
+			t.Errorf("Test case %q got branch %q, want %q", tc.description, g, w)
+		}
+	}
+}
+
+// writeFiles writes files to dir, making any directories they need. The
+// files are keyed by slash separated paths relative to dir.
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(p), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.WriteFile(p, []byte(content), 0o644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
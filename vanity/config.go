@@ -0,0 +1,407 @@
+package vanity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Config describes a vanity domain and the repositories hosted at it.
+type Config struct {
+	Domain       string       `json:"domain"`
+	DocsDomain   string       `json:"docsDomain"`
+	Index        bool         `json:"index"`
+	Branch       string       `json:"branch"`
+	Hosts        []Host       `json:"hosts"`
+	Proxy        string       `json:"proxy"`
+	Templates    string       `json:"templates"`
+	Assets       string       `json:"assets"`
+	Theme        string       `json:"theme"`
+	Sections     []Section    `json:"sections"`
+	Repositories []Repository `json:"repositories"`
+}
+
+// BranchClone is the branch that, set on a repository or at the top level of
+// the config, has the branch of each repository read from its local clone by
+// ReadClones.
+const BranchClone = "clone"
+
+// repositoryForPackage returns the repository whose prefix is the longest
+// prefix of pkg. Packages below a prefix match even if they are not listed as
+// subs.
+func (c Config) repositoryForPackage(pkg string) (Repository, bool) {
+	var match Repository
+	found := false
+	for _, r := range c.allRepositories() {
+		if r.Prefix != "" && pkg != r.Prefix && !strings.HasPrefix(pkg, r.Prefix+"/") {
+			continue
+		}
+		if found && len(r.Prefix) <= len(match.Prefix) {
+			continue
+		}
+		match = r
+		found = true
+	}
+	return match, found
+}
+
+// allRepositories returns the repositories in the config followed by the
+// repositories for their major versions.
+func (c Config) allRepositories() []Repository {
+	repos := append([]Repository{}, c.Repositories...)
+	for _, r := range c.Repositories {
+		repos = append(repos, r.versionRepositories()...)
+	}
+	return repos
+}
+
+// proxy returns the URL of the module proxy that serves the modules in
+// repository r, or an empty string if the go command downloads them from the
+// repository directly.
+func (c Config) proxy(r Repository) string {
+	p := r.Proxy
+	if p == "" {
+		p = c.Proxy
+	}
+	if p == "direct" {
+		return ""
+	}
+	return p
+}
+
+// Repository is a repository hosting one or more packages below Prefix.
+type Repository struct {
+	Prefix      string     `json:"prefix"`
+	Subs        []Sub      `json:"subs"`
+	Type        string     `json:"type"`
+	URL         string     `json:"url"`
+	Main        bool       `json:"main"`
+	Hidden      bool       `json:"hidden"`
+	SourceURLs  SourceURLs `json:"source"`
+	Website     Website    `json:"website"`
+	Branch      string     `json:"branch"`
+	Clone       string     `json:"clone"`
+	Discover    bool       `json:"discover"`
+	Proxy       string     `json:"proxy"`
+	Subdir      string     `json:"subdir"`
+	Versions    []Version  `json:"versions"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags"`
+
+	// version is true for the repositories made for each major version,
+	// whose go-import and go-source tags have the prefixes importPrefix and
+	// sourcePrefix. They are the prefix of the repository they are a version
+	// of when they share its repository or branch.
+	version                    bool
+	importPrefix, sourcePrefix string
+}
+
+func (r Repository) PrefixPath() string {
+	if r.Prefix == "" {
+		return ""
+	} else {
+		return "/" + r.Prefix
+	}
+}
+
+func (r Repository) Packages() []string {
+	pkgs := []string{r.Prefix}
+	for i := range r.Subs {
+		pkgs = append(pkgs, r.SubPath(i))
+	}
+	return pkgs
+}
+
+func (r Repository) SubPath(i int) string {
+	return path.Join(r.Prefix, r.Subs[i].Name)
+}
+
+func (r Repository) VersionPath(i int) string {
+	return path.Join(r.Prefix, r.Versions[i].Version)
+}
+
+// versionRepositories returns a repository for each major version of r,
+// with the prefix of the version and the same subs.
+func (r Repository) versionRepositories() []Repository {
+	var repos []Repository
+	for i, v := range r.Versions {
+		vr := r
+		vr.Prefix = r.VersionPath(i)
+		vr.Versions = nil
+		vr.version = true
+		vr.importPrefix = r.Prefix
+		vr.sourcePrefix = r.Prefix
+		if v.Branch != "" {
+			vr.Branch = v.Branch
+			vr.sourcePrefix = vr.Prefix
+		}
+		if v.URL != "" {
+			vr.URL = v.URL
+			vr.Subdir = ""
+			vr.SourceURLs = SourceURLs{}
+			vr.importPrefix = vr.Prefix
+			vr.sourcePrefix = vr.Prefix
+		}
+		repos = append(repos, vr)
+	}
+	return repos
+}
+
+// goImportPrefix returns the prefix of the go-import tag of r.
+func (r Repository) goImportPrefix() string {
+	if r.version {
+		return r.importPrefix
+	}
+	return r.Prefix
+}
+
+// goSourcePrefix returns the prefix of the go-source tag of r.
+func (r Repository) goSourcePrefix() string {
+	if r.version {
+		return r.sourcePrefix
+	}
+	return r.Prefix
+}
+
+// Version is a major version of the module in a repository, such as v2,
+// whose packages are below the repository prefix at the version. Versions
+// are developed in a major version subdirectory of the repository, unless
+// they have their own branch or URL.
+type Version struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+	Branch  string `json:"branch"`
+}
+
+func (v *Version) UnmarshalJSON(raw []byte) error {
+	*v = Version{}
+
+	err := json.Unmarshal(raw, &v.Version)
+	if err == nil {
+		return nil
+	}
+
+	versionWithTags := struct {
+		Version string `json:"version"`
+		URL     string `json:"url"`
+		Branch  string `json:"branch"`
+	}{}
+	err = json.Unmarshal(raw, &versionWithTags)
+	if err != nil {
+		return err
+	}
+	*v = Version(versionWithTags)
+	return nil
+}
+
+// Section is a titled list of repositories on the index page. A section lists
+// the repositories with any of its tags or prefixes, or if it has neither,
+// every repository not listed in another section.
+type Section struct {
+	Title    string   `json:"title"`
+	Tags     []string `json:"tags"`
+	Prefixes []string `json:"prefixes"`
+}
+
+// Sub is a package inside a repository, named relative to the repository
+// prefix.
+type Sub struct {
+	Name        string   `json:"name"`
+	Hidden      bool     `json:"hidden"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+func (s *Sub) UnmarshalJSON(raw []byte) error {
+	*s = Sub{}
+
+	err := json.Unmarshal(raw, &s.Name)
+	if err == nil {
+		return nil
+	}
+
+	subWithTags := struct {
+		Name        string   `json:"name"`
+		Hidden      bool     `json:"hidden"`
+		Description string   `json:"description"`
+		Tags        []string `json:"tags"`
+	}{}
+	err = json.Unmarshal(raw, &subWithTags)
+	if err != nil {
+		return err
+	}
+	*s = Sub(subWithTags)
+	return nil
+}
+
+// SourceURLs are the URL templates for the go-source meta tag.
+type SourceURLs struct {
+	Home string `json:"home"`
+	Dir  string `json:"dir"`
+	File string `json:"file"`
+}
+
+// Website is the home page for a repository, used in place of the docs URL.
+type Website struct {
+	URL string `json:"url"`
+}
+
+// Formats that configs can be written in.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// FormatForFilename returns the format of the config file at filename, based
+// on its extension. Files with an unrecognized extension are JSON.
+func FormatForFilename(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// ParseConfig reads a JSON config from r. Repositories are sorted by prefix.
+func ParseConfig(r io.Reader) (Config, error) {
+	return ParseConfigFormat(r, FormatJSON)
+}
+
+// ParseConfigFormat reads a config in the format from r. YAML and TOML
+// configs have the same fields as JSON configs, and subs can be written as a
+// string or as a table the same as in JSON. Repositories are sorted by
+// prefix.
+//
+// The config is validated and if it has unknown fields or any of the problems
+// reported by Validate a *ValidationError is returned. Problems include the
+// line and column they are at.
+func ParseConfigFormat(r io.Reader, format string) (Config, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return Config{}, err
+	}
+
+	var v interface{}
+	var positions map[string]position
+	switch format {
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&v)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			// The offset is after the byte that caused the error.
+			pos := offsetPosition(data, int(syntaxErr.Offset)-1)
+			return Config{}, fmt.Errorf("%d:%d: %w", pos.line, pos.column, err)
+		} else if err != nil {
+			return Config{}, err
+		}
+		positions = jsonPositions(data)
+	case FormatYAML:
+		var n yaml.Node
+		err = yaml.Unmarshal(data, &n)
+		if err != nil {
+			return Config{}, err
+		}
+		err = n.Decode(&v)
+		if err != nil {
+			return Config{}, err
+		}
+		positions = yamlPositions(&n)
+	case FormatTOML:
+		var t map[string]interface{}
+		err = toml.Unmarshal(data, &t)
+		if err != nil {
+			return Config{}, err
+		}
+		// TOML tables and arrays of tables decode into typed maps and
+		// slices, so pass them through JSON to get the same generic
+		// values as the other formats.
+		tj, err := json.Marshal(t)
+		if err != nil {
+			return Config{}, err
+		}
+		err = json.Unmarshal(tj, &v)
+		if err != nil {
+			return Config{}, err
+		}
+		positions = tomlPositions(data)
+	default:
+		return Config{}, fmt.Errorf("unsupported config format %q", format)
+	}
+	if v == nil {
+		v = map[string]interface{}{}
+	}
+
+	var c Config
+	var problems []Problem
+	checkFields(v, reflect.TypeOf(c), "", func(path, format string, args ...interface{}) {
+		problems = append(problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+	})
+	if format != FormatJSON {
+		data, err = json.Marshal(v)
+		if err != nil {
+			return Config{}, err
+		}
+	}
+	// Unknown fields are ignored when decoding, so the config is still
+	// validated when there are any. Values of the wrong type fail to decode,
+	// and have already been reported by checkFields.
+	err = json.Unmarshal(data, &c)
+	if err == nil {
+		problems = append(problems, c.validate()...)
+	} else if len(problems) == 0 {
+		return Config{}, err
+	}
+	if len(problems) > 0 {
+		for i := range problems {
+			problems[i].Repository, problems[i].Prefix = problemRepository(v, problems[i].Path)
+			pos := locate(positions, problems[i].Path)
+			problems[i].Line, problems[i].Column = pos.line, pos.column
+		}
+		sort.SliceStable(problems, func(i, j int) bool {
+			pi, pj := problems[i], problems[j]
+			return pi.Line < pj.Line || pi.Line == pj.Line && pi.Column < pj.Column
+		})
+		return Config{}, &ValidationError{Problems: problems}
+	}
+
+	sort.Slice(c.Repositories, func(i, j int) bool {
+		return c.Repositories[i].Prefix < c.Repositories[j].Prefix
+	})
+
+	return c, nil
+}
+
+// problemRepository returns the index and prefix of the repository that the
+// path is in, using the undecoded config v. The index is -1 if the path is not
+// in a repository.
+func problemRepository(v interface{}, path string) (int, string) {
+	var i int
+	_, err := fmt.Sscanf(path, "repositories[%d]", &i)
+	if err != nil {
+		return -1, ""
+	}
+	m, _ := v.(map[string]interface{})
+	repos, _ := m["repositories"].([]interface{})
+	if i < 0 || i >= len(repos) {
+		return i, ""
+	}
+	repo, _ := repos[i].(map[string]interface{})
+	prefix, _ := repo["prefix"].(string)
+	return i, prefix
+}
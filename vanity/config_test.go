@@ -1,4 +1,4 @@
-package main
+package vanity
 
 import (
 	"reflect"
@@ -7,9 +7,11 @@ import (
 )
 
 func TestParseConfigNoIndex(t *testing.T) {
-	r := strings.NewReader(`{}`)
+	r := strings.NewReader(`{
+  "domain": "example.com"
+}`)
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -21,10 +23,11 @@ func TestParseConfigNoIndex(t *testing.T) {
 
 func TestParseConfigIndex(t *testing.T) {
 	r := strings.NewReader(`{
+  "domain": "example.com",
   "index": true
 }`)
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -36,9 +39,11 @@ func TestParseConfigIndex(t *testing.T) {
 
 func TestParseConfigPackages(t *testing.T) {
 	r := strings.NewReader(`{
+  "domain": "example.com",
   "repositories": [
     {
       "prefix": "foo",
+      "url": "https://github.com/example/foo",
       "subs": [
         "bar",
 		"car",
@@ -49,7 +54,7 @@ func TestParseConfigPackages(t *testing.T) {
   ]
 }`)
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -70,9 +75,11 @@ func TestParseConfigPackages(t *testing.T) {
 
 func TestParseConfigHiddenPackages(t *testing.T) {
 	r := strings.NewReader(`{
+  "domain": "example.com",
   "repositories": [
     {
       "prefix": "foo",
+      "url": "https://github.com/example/foo",
       "subs": [
         "bar",
 		"car",
@@ -83,13 +90,13 @@ func TestParseConfigHiddenPackages(t *testing.T) {
   ]
 }`)
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
 	s := c.Repositories[0].Subs
 
-	e := []sub{
+	e := []Sub{
 		{Name: "bar"},
 		{Name: "car"},
 		{Name: "car/dar", Hidden: true},
@@ -115,12 +122,12 @@ func TestParseConfigGithubMinimal(t *testing.T) {
   ]
 }`)
 
-	e := config{
+	e := Config{
 		Domain: "4d63.com",
-		Repositories: []repository{
+		Repositories: []Repository{
 			{
 				Prefix: "optional",
-				Subs: []sub{
+				Subs: []Sub{
 					{Name: "template"},
 				},
 				URL: "https://github.com/leighmcculloch/go-optional",
@@ -128,7 +135,7 @@ func TestParseConfigGithubMinimal(t *testing.T) {
 		},
 	}
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -161,29 +168,29 @@ func TestParseConfigGithubComplete(t *testing.T) {
   ]
 }`)
 
-	e := config{
+	e := Config{
 		Domain: "4d63.com",
-		Repositories: []repository{
+		Repositories: []Repository{
 			{
 				Prefix: "optional",
-				Subs: []sub{
+				Subs: []Sub{
 					{Name: "template"},
 				},
 				Type: "git",
 				URL:  "https://github.com/leighmcculloch/go-optional",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://github.com/leighmcculloch/go-optional",
 					Dir:  "https://github.com/leighmcculloch/go-optional/tree/master{/dir}",
 					File: "https://github.com/leighmcculloch/go-optional/blob/master{/dir}/{file}#L{line}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://github.com/leighmcculloch/go-optional",
 				},
 			},
 		},
 	}
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -207,12 +214,12 @@ func TestParseConfigGitlabMinimal(t *testing.T) {
   ]
 }`)
 
-	e := config{
+	e := Config{
 		Domain: "4d63.com",
-		Repositories: []repository{
+		Repositories: []Repository{
 			{
 				Prefix: "optional",
-				Subs: []sub{
+				Subs: []Sub{
 					{Name: "template"},
 				},
 				URL: "https://gitlab.com/leighmcculloch/go-optional",
@@ -220,7 +227,7 @@ func TestParseConfigGitlabMinimal(t *testing.T) {
 		},
 	}
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -253,29 +260,29 @@ func TestParseConfigGitlabComplete(t *testing.T) {
   ]
 }`)
 
-	e := config{
+	e := Config{
 		Domain: "4d63.com",
-		Repositories: []repository{
+		Repositories: []Repository{
 			{
 				Prefix: "optional",
-				Subs: []sub{
+				Subs: []Sub{
 					{Name: "template"},
 				},
 				Type: "git",
 				URL:  "https://gitlab.com/leighmcculloch/go-optional",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://gitlab.com/leighmcculloch/go-optional",
 					Dir:  "https://gitlab.com/leighmcculloch/go-optional/tree/master{/dir}",
 					File: "https://gitlab.com/leighmcculloch/go-optional/blob/master{/dir}/{file}#L{line}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://gitlab.com/leighmcculloch/go-optional",
 				},
 			},
 		},
 	}
 
-	c, err := parseConfig(r)
+	c, err := ParseConfig(r)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -284,3 +291,176 @@ func TestParseConfigGitlabComplete(t *testing.T) {
 		t.Errorf("Got config %#v, want %#v", c, e)
 	}
 }
+
+func TestParseConfigVersions(t *testing.T) {
+	r := strings.NewReader(`{
+  "domain": "4d63.com",
+  "repositories": [
+    {
+      "prefix": "optional",
+      "url": "https://github.com/leighmcculloch/go-optional",
+      "versions": [
+        "v2",
+        {"version": "v3", "branch": "v3"},
+        {"version": "v4", "url": "https://github.com/leighmcculloch/go-optional-v4"}
+      ]
+    }
+  ]
+}`)
+
+	e := Config{
+		Domain: "4d63.com",
+		Repositories: []Repository{
+			{
+				Prefix: "optional",
+				URL:    "https://github.com/leighmcculloch/go-optional",
+				Versions: []Version{
+					{Version: "v2"},
+					{Version: "v3", Branch: "v3"},
+					{Version: "v4", URL: "https://github.com/leighmcculloch/go-optional-v4"},
+				},
+			},
+		},
+	}
+
+	c, err := ParseConfig(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(c, e) {
+		t.Errorf("Got config %#v, want %#v", c, e)
+	}
+}
+
+func TestParseConfigFormats(t *testing.T) {
+	e := Config{
+		Domain: "4d63.com",
+		Index:  true,
+		Repositories: []Repository{
+			{
+				Prefix: "optional",
+				Subs: []Sub{
+					{Name: "template"},
+					{Name: "internal/thing", Hidden: true},
+				},
+				URL: "https://github.com/leighmcculloch/go-optional",
+				Website: Website{
+					URL: "https://github.com/leighmcculloch/go-optional",
+				},
+			},
+			{
+				Prefix: "vangen",
+				URL:    "https://github.com/leighmcculloch/vangen",
+				Main:   true,
+			},
+		},
+	}
+
+	testCases := []struct {
+		format string
+		config string
+	}{
+		{
+			format: FormatJSON,
+			config: `{
+  "domain": "4d63.com",
+  "index": true,
+  "repositories": [
+    {
+      "prefix": "vangen",
+      "url": "https://github.com/leighmcculloch/vangen",
+      "main": true
+    },
+    {
+      "prefix": "optional",
+      "subs": [
+        "template",
+        { "name": "internal/thing", "hidden": true }
+      ],
+      "url": "https://github.com/leighmcculloch/go-optional",
+      "website": {
+        "url": "https://github.com/leighmcculloch/go-optional"
+      }
+    }
+  ]
+}`,
+		},
+		{
+			format: FormatYAML,
+			config: `# Comments are allowed.
+domain: 4d63.com
+index: true
+repositories:
+  - prefix: vangen
+    url: https://github.com/leighmcculloch/vangen
+    main: true
+  - prefix: optional
+    subs:
+      - template
+      # Hidden because it is only used by the tests.
+      - name: internal/thing
+        hidden: true
+    url: https://github.com/leighmcculloch/go-optional
+    website:
+      url: https://github.com/leighmcculloch/go-optional
+`,
+		},
+		{
+			format: FormatTOML,
+			config: `# Comments are allowed.
+domain = "4d63.com"
+index = true
+
+[[repositories]]
+prefix = "vangen"
+url = "https://github.com/leighmcculloch/vangen"
+main = true
+
+[[repositories]]
+prefix = "optional"
+subs = [
+  "template",
+  # Hidden because it is only used by the tests.
+  { name = "internal/thing", hidden = true },
+]
+url = "https://github.com/leighmcculloch/go-optional"
+website = { url = "https://github.com/leighmcculloch/go-optional" }
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		c, err := ParseConfigFormat(strings.NewReader(tc.config), tc.format)
+		if err != nil {
+			t.Errorf("Format %s got err %v", tc.format, err)
+			continue
+		}
+		if !reflect.DeepEqual(c, e) {
+			t.Errorf("Format %s got config %#v, want %#v", tc.format, c, e)
+		}
+	}
+}
+
+func TestParseConfigFormatUnsupported(t *testing.T) {
+	_, err := ParseConfigFormat(strings.NewReader("{}"), "xml")
+	if err == nil {
+		t.Errorf("Got no error, want error")
+	}
+}
+
+func TestFormatForFilename(t *testing.T) {
+	testCases := map[string]string{
+		"vangen.json":        FormatJSON,
+		"vangen.yaml":        FormatYAML,
+		"vangen.yml":         FormatYAML,
+		"config/vangen.YAML": FormatYAML,
+		"vangen.toml":        FormatTOML,
+		"vangen":             FormatJSON,
+	}
+	for filename, expected := range testCases {
+		if g := FormatForFilename(filename); g != expected {
+			t.Errorf("Filename %q got format %q, want %q", filename, g, expected)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package vanity
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// discoverSubs returns the slash separated paths, relative to dir, of the
+// directories below dir that contain a package that can be imported from
+// outside of the module rooted at dir.
+//
+// Directories named internal, testdata or vendor, directories whose names
+// begin with _ or ., and directories containing a nested module, are skipped
+// along with everything below them, the same as the go command.
+func discoverSubs(dir string) ([]string, error) {
+	var subs []string
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == dir {
+			return nil
+		}
+
+		name := d.Name()
+		if name == "internal" || name == "testdata" || name == "vendor" || strings.HasPrefix(name, "_") || strings.HasPrefix(name, ".") {
+			return filepath.SkipDir
+		}
+		if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+			return filepath.SkipDir
+		}
+
+		ok, err := isPackageDir(p)
+		if err != nil || !ok {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		subs = append(subs, filepath.ToSlash(rel))
+		return nil
+	})
+	return subs, err
+}
+
+// isPackageDir returns true if dir contains the Go files of a package, not
+// counting test files.
+func isPackageDir(dir string) (bool, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	var noGoErr *build.NoGoError
+	if errors.As(err, &noGoErr) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("reading package: %w", err)
+	}
+	return len(pkg.GoFiles) > 0 || len(pkg.CgoFiles) > 0, nil
+}
+
+// newSubs returns the discovered names of subs of r that are valid package
+// paths and whose packages, in r and in each of its major versions, are not
+// in packages. Directories named for a major version in versions are skipped
+// this way. The packages of the names returned are added to packages.
+func newSubs(r Repository, discovered []string, packages map[string]bool) []string {
+	var names []string
+	for _, name := range discovered {
+		if checkPackagePath(name) != nil {
+			continue
+		}
+		pkgs := []string{path.Join(r.Prefix, name)}
+		for i := range r.Versions {
+			pkgs = append(pkgs, path.Join(r.VersionPath(i), name))
+		}
+		generated := false
+		for _, pkg := range pkgs {
+			generated = generated || packages[pkg]
+		}
+		if generated {
+			continue
+		}
+		for _, pkg := range pkgs {
+			packages[pkg] = true
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// mergeSubs returns subs with a sub added for each of the discovered names
+// that is not already in subs, sorted by name.
+func mergeSubs(subs []Sub, discovered []string) []Sub {
+	merged := append([]Sub{}, subs...)
+	listed := map[string]bool{}
+	for _, s := range subs {
+		listed[s.Name] = true
+	}
+	for _, name := range discovered {
+		if !listed[name] {
+			merged = append(merged, Sub{Name: name})
+		}
+	}
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Name < merged[j].Name
+	})
+	return merged
+}
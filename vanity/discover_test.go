@@ -0,0 +1,107 @@
+package vanity
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadClonesDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"repo/go.mod":                    "module example.com/pkg1\n",
+		"repo/pkg1.go":                   "package pkg1\n",
+		"repo/a/a.go":                    "package a\n",
+		"repo/a/b/b.go":                  "package b\n",
+		"repo/a/internal/c/c.go":         "package c\n",
+		"repo/cmd/tool/main.go":          "package main\n",
+		"repo/docs/README.md":            "docs\n",
+		"repo/internal/d/d.go":           "package d\n",
+		"repo/testdata/e/e.go":           "package e\n",
+		"repo/vendor/example.com/f/f.go": "package f\n",
+		"repo/_tools/g.go":               "package g\n",
+		"repo/.github/h.go":              "package h\n",
+		"repo/nested/go.mod":             "module example.com/pkg1/nested\n",
+		"repo/nested/nested.go":          "package nested\n",
+		"repo/nested/i/i.go":             "package i\n",
+		"repo/onlytests/j_test.go":       "package j\n",
+		"repo/go/k/k.go":                 "package k\n",
+		"repo/bad name/l.go":             "package l\n",
+		"repo/v2/v2.go":                  "package v2\n",
+	})
+
+	testCases := []struct {
+		description  string
+		r            Repository
+		other        Repository
+		expectedSubs []Sub
+	}{
+		{
+			description: "discover off",
+			r:           Repository{Prefix: "pkg1", Branch: "main", Clone: "repo", Subs: []Sub{{Name: "a"}}},
+			expectedSubs: []Sub{
+				{Name: "a"},
+			},
+		},
+		{
+			description: "discover",
+			r:           Repository{Prefix: "pkg1", Branch: "main", Clone: "repo", Discover: true},
+			expectedSubs: []Sub{
+				{Name: "a"},
+				{Name: "a/b"},
+				{Name: "cmd/tool"},
+				{Name: "go/k"},
+				{Name: "v2"},
+			},
+		},
+		{
+			description: "explicit subs",
+			r:           Repository{Prefix: "pkg1", Branch: "main", Clone: "repo", Discover: true, Subs: []Sub{{Name: "cmd/tool", Hidden: true}, {Name: "extra"}}},
+			expectedSubs: []Sub{
+				{Name: "a"},
+				{Name: "a/b"},
+				{Name: "cmd/tool", Hidden: true},
+				{Name: "extra"},
+				{Name: "go/k"},
+				{Name: "v2"},
+			},
+		},
+		{
+			description: "subdir",
+			r:           Repository{Prefix: "pkg1", Branch: "main", Clone: "repo", Discover: true, Subdir: "go"},
+			expectedSubs: []Sub{
+				{Name: "k"},
+			},
+		},
+		{
+			description: "versions",
+			r:           Repository{Prefix: "pkg1", Branch: "main", Clone: "repo", Discover: true, Versions: []Version{{Version: "v2"}}},
+			expectedSubs: []Sub{
+				{Name: "a"},
+				{Name: "a/b"},
+				{Name: "cmd/tool"},
+				{Name: "go/k"},
+			},
+		},
+		{
+			description:  "other repository",
+			r:            Repository{Prefix: "pkg1", Branch: "main", Clone: "repo", Discover: true, Subdir: "go"},
+			other:        Repository{Prefix: "pkg1/k", URL: "https://github.com/example/go-pkg1-k"},
+			expectedSubs: []Sub{},
+		},
+	}
+
+	for _, tc := range testCases {
+		c := Config{Repositories: []Repository{tc.r}}
+		if tc.other.Prefix != "" {
+			c.Repositories = append(c.Repositories, tc.other)
+		}
+		err := c.ReadClones(dir)
+		if err != nil {
+			t.Errorf("Test case %q got err %v", tc.description, err)
+			continue
+		}
+		if g, w := c.Repositories[0].Subs, tc.expectedSubs; !reflect.DeepEqual(g, w) {
+			t.Errorf("Test case %q got subs %v, want %v", tc.description, g, w)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package vanity
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mapFS is an in-memory file system of files keyed by slash separated path.
+// Directories are implied by the paths of the files they contain.
+type mapFS map[string][]byte
+
+func (m mapFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if data, ok := m[name]; ok {
+		return &mapFile{
+			info:   fileInfo{name: path.Base(name), size: int64(len(data))},
+			Reader: bytes.NewReader(data),
+		}, nil
+	}
+
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	children := map[string]fileInfo{}
+	for n, data := range m {
+		if !strings.HasPrefix(n, prefix) {
+			continue
+		}
+		child, _, isDir := strings.Cut(n[len(prefix):], "/")
+		if isDir {
+			children[child] = fileInfo{name: child, dir: true}
+		} else {
+			children[child] = fileInfo{name: child, size: int64(len(data))}
+		}
+	}
+	if len(children) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	entries := make([]fs.DirEntry, 0, len(children))
+	for _, c := range children {
+		entries = append(entries, c)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return &mapDir{
+		info:    fileInfo{name: path.Base(name), dir: true},
+		entries: entries,
+	}, nil
+}
+
+func (m mapFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+type mapFile struct {
+	info fileInfo
+	*bytes.Reader
+}
+
+func (f *mapFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *mapFile) Close() error               { return nil }
+
+type mapDir struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *mapDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *mapDir) Close() error               { return nil }
+
+func (d *mapDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *mapDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(remaining) {
+		n = len(remaining)
+	}
+	d.offset += n
+	return remaining[:n], nil
+}
+
+// fileInfo describes a file or directory in a mapFS.
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i fileInfo) Name() string { return i.name }
+func (i fileInfo) Size() int64  { return i.size }
+func (i fileInfo) IsDir() bool  { return i.dir }
+func (i fileInfo) Sys() any     { return nil }
+
+func (i fileInfo) ModTime() time.Time { return time.Time{} }
+
+func (i fileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+func (i fileInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i fileInfo) Info() (fs.FileInfo, error) { return i, nil }
@@ -0,0 +1,189 @@
+package vanity
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// indexHTML is the built-in template for the index page. Its blocks can be
+// replaced by themes and by the templates passed to ParseTemplates.
+const indexHTML = `<!DOCTYPE html>
+<html>
+<head>
+{{block "head" .}}<meta charset="utf-8">
+<title>{{.Domain}} Go Modules</title>
+{{end}}{{block "style" .}}<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+</style>
+{{end}}</head>
+<body>
+<div class="content">
+
+{{block "header" .}}<h2>{{.Domain}} Go Modules</h2>
+{{end}}
+{{- range .Sections}}
+{{block "index-section" .}}<h3>{{.Title}}:</h3>
+
+<ul>
+{{range $_, $r := .Repositories -}}
+<li>
+<a href="{{$.BasePath}}/{{$r.Prefix}}">{{$r.Prefix}}</a>{{range $i, $v := $r.Versions}} <a href="{{$.BasePath}}/{{$r.VersionPath $i}}">{{$v.Version}}</a>{{end}}{{with $r.Description}} - {{.}}{{end}}
+{{if .Subs -}}<ul>{{end -}}
+{{range $_, $s := .Subs -}}{{if not $s.Hidden -}}<li><a href="{{$.BasePath}}/{{$r.Prefix}}/{{$s.Name}}">{{$s.Name}}</a>{{with $s.Description}} - {{.}}{{end}}</li>{{end -}}{{end -}}
+{{if .Subs -}}</ul>{{end -}}
+</li>
+{{end -}}
+</ul>
+{{end}}{{end}}
+{{block "footer" .}}<hr/>
+
+Generated by <a href="https://4d63.com/vangen">vangen</a>.
+{{end}}
+</div>
+</body>
+</html>`
+
+// IndexData is the data that the index page is rendered with.
+type IndexData struct {
+	// Domain is the vanity domain.
+	Domain string
+	// BasePath is the path the pages are served below, such as /go, or
+	// empty if they are served at the root of the domain.
+	BasePath string
+	// MainRepositories are the repositories with Main set, listed as tools.
+	MainRepositories []Repository
+	// PackageRepositories are the other repositories, listed as libraries.
+	PackageRepositories []Repository
+	// Sections are the sections of the index, each listing repositories.
+	// They are the sections in the config, or if it has none, a section of
+	// tools and a section of libraries.
+	Sections []IndexSection
+}
+
+// IndexSection is a titled list of repositories on the index page. The
+// index-section block is rendered with each section, so each has the
+// BasePath of the index for linking to the repositories.
+type IndexSection struct {
+	Title        string
+	Repositories []Repository
+	BasePath     string
+}
+
+// generateIndex writes the index page listing the repositories in c,
+// rendered with tmpl, linking to pages below basePath.
+func generateIndex(w io.Writer, tmpl *template.Template, c Config, basePath string) error {
+	mainRepositories := []Repository{}
+	packageRepositories := []Repository{}
+	for _, r := range c.Repositories {
+		if r.Main {
+			mainRepositories = append(mainRepositories, r)
+		} else {
+			packageRepositories = append(packageRepositories, r)
+		}
+	}
+
+	basePath = strings.TrimSuffix(basePath, "/")
+	sections := c.indexSections()
+	for i := range sections {
+		sections[i].BasePath = basePath
+	}
+	data := IndexData{
+		Domain:              c.Domain,
+		BasePath:            basePath,
+		MainRepositories:    mainRepositories,
+		PackageRepositories: packageRepositories,
+		Sections:            sections,
+	}
+
+	err := tmpl.Execute(w, data)
+	if err != nil {
+		return fmt.Errorf("generating template: %v", err)
+	}
+
+	return nil
+}
+
+// indexSections returns the sections of the index page. Hidden repositories
+// are not listed, except that without sections in the config the
+// repositories with Main set are all listed as tools and the others as
+// libraries.
+//
+// A section lists the repositories with its prefixes in the order they are
+// given, followed by the repositories and subs with any of its tags, in the
+// order of c.Repositories, which ParseConfig sorts by prefix. Subs are
+// listed as repositories with the sub's path as their prefix. Sections with
+// neither list the repositories that no other section does.
+func (c Config) indexSections() []IndexSection {
+	if len(c.Sections) == 0 {
+		tools := []Repository{}
+		libraries := []Repository{}
+		for _, r := range c.Repositories {
+			if r.Main {
+				tools = append(tools, r)
+			} else if !r.Hidden {
+				libraries = append(libraries, r)
+			}
+		}
+		return []IndexSection{
+			{Title: "Tools", Repositories: tools},
+			{Title: "Libraries", Repositories: libraries},
+		}
+	}
+
+	sections := make([]IndexSection, len(c.Sections))
+	listed := map[string]bool{}
+	for i, s := range c.Sections {
+		sections[i] = IndexSection{Title: s.Title, Repositories: []Repository{}}
+		inSection := map[string]bool{}
+		add := func(r Repository) {
+			if r.Hidden || inSection[r.Prefix] {
+				return
+			}
+			inSection[r.Prefix] = true
+			listed[r.Prefix] = true
+			sections[i].Repositories = append(sections[i].Repositories, r)
+		}
+		for _, p := range s.Prefixes {
+			for _, r := range c.Repositories {
+				if r.Prefix == p {
+					add(r)
+				}
+			}
+		}
+		for _, r := range c.Repositories {
+			if hasTag(s.Tags, r.Tags) {
+				add(r)
+			}
+			for j, sub := range r.Subs {
+				if !r.Hidden && !sub.Hidden && hasTag(s.Tags, sub.Tags) {
+					add(Repository{Prefix: r.SubPath(j), Description: sub.Description, Tags: sub.Tags})
+				}
+			}
+		}
+	}
+	for i, s := range c.Sections {
+		if len(s.Tags) > 0 || len(s.Prefixes) > 0 {
+			continue
+		}
+		for _, r := range c.Repositories {
+			if !r.Hidden && !listed[r.Prefix] {
+				sections[i].Repositories = append(sections[i].Repositories, r)
+			}
+		}
+	}
+	return sections
+}
+
+// hasTag returns true if any of tags are in sectionTags.
+func hasTag(sectionTags, tags []string) bool {
+	for _, t := range tags {
+		if contains(sectionTags, t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,288 @@
+package vanity
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	testCases := []struct {
+		description string
+		domain      string
+		sections    []Section
+		r           []Repository
+		expectedOut string
+		expectedErr error
+	}{
+		{
+			description: "basic",
+			domain:      "example.com",
+			r: []Repository{
+				{
+					Prefix: "pkg1",
+					Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+					Main:   true,
+				},
+				{
+					Prefix: "pkg2",
+					Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2/subsubpkg1"}},
+				},
+				{
+					Prefix: "pkg3",
+					Hidden: true,
+				},
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com Go Modules</title>
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+</style>
+</head>
+<body>
+<div class="content">
+
+<h2>example.com Go Modules</h2>
+
+<h3>Tools:</h3>
+
+<ul>
+<li>
+<a href="/pkg1">pkg1</a>
+<ul><li><a href="/pkg1/subpkg1">subpkg1</a></li><li><a href="/pkg1/subpkg2">subpkg2</a></li></ul></li>
+</ul>
+
+<h3>Libraries:</h3>
+
+<ul>
+<li>
+<a href="/pkg2">pkg2</a>
+<ul><li><a href="/pkg2/subpkg1">subpkg1</a></li><li><a href="/pkg2/subpkg2/subsubpkg1">subpkg2/subsubpkg1</a></li></ul></li>
+</ul>
+
+<hr/>
+
+Generated by <a href="https://4d63.com/vangen">vangen</a>.
+
+</div>
+</body>
+</html>`,
+			expectedErr: nil,
+		},
+		{
+			description: "hidden sub-package",
+			domain:      "example.com",
+			r: []Repository{
+				{
+					Prefix: "pkg1",
+					Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}, {Name: "subpkg3", Hidden: true}},
+					Main:   true,
+				},
+				{
+					Prefix: "pkg2",
+					Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2/subsubpkg1"}, {Name: "subpkg2/subsubpkg2", Hidden: true}},
+				},
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com Go Modules</title>
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+</style>
+</head>
+<body>
+<div class="content">
+
+<h2>example.com Go Modules</h2>
+
+<h3>Tools:</h3>
+
+<ul>
+<li>
+<a href="/pkg1">pkg1</a>
+<ul><li><a href="/pkg1/subpkg1">subpkg1</a></li><li><a href="/pkg1/subpkg2">subpkg2</a></li></ul></li>
+</ul>
+
+<h3>Libraries:</h3>
+
+<ul>
+<li>
+<a href="/pkg2">pkg2</a>
+<ul><li><a href="/pkg2/subpkg1">subpkg1</a></li><li><a href="/pkg2/subpkg2/subsubpkg1">subpkg2/subsubpkg1</a></li></ul></li>
+</ul>
+
+<hr/>
+
+Generated by <a href="https://4d63.com/vangen">vangen</a>.
+
+</div>
+</body>
+</html>`,
+			expectedErr: nil,
+		},
+		{
+			description: "versions",
+			domain:      "example.com",
+			r: []Repository{
+				{
+					Prefix:   "pkg1",
+					Subs:     []Sub{{Name: "subpkg1"}},
+					Versions: []Version{{Version: "v2"}, {Version: "v3"}},
+				},
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com Go Modules</title>
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+</style>
+</head>
+<body>
+<div class="content">
+
+<h2>example.com Go Modules</h2>
+
+<h3>Tools:</h3>
+
+<ul>
+</ul>
+
+<h3>Libraries:</h3>
+
+<ul>
+<li>
+<a href="/pkg1">pkg1</a> <a href="/pkg1/v2">v2</a> <a href="/pkg1/v3">v3</a>
+<ul><li><a href="/pkg1/subpkg1">subpkg1</a></li></ul></li>
+</ul>
+
+<hr/>
+
+Generated by <a href="https://4d63.com/vangen">vangen</a>.
+
+</div>
+</body>
+</html>`,
+			expectedErr: nil,
+		},
+		{
+			description: "sections",
+			domain:      "example.com",
+			sections: []Section{
+				{Title: "Featured", Prefixes: []string{"pkg3", "pkg1"}},
+				{Title: "Databases", Tags: []string{"db"}},
+				{Title: "Other"},
+			},
+			r: []Repository{
+				{
+					Prefix:      "pkg1",
+					Description: "Package one.",
+					Subs: []Sub{
+						{Name: "subpkg1", Description: "Sub one."},
+						{Name: "subpkg2", Description: "Sub two.", Tags: []string{"db"}},
+						{Name: "subpkg3", Tags: []string{"db"}, Hidden: true},
+					},
+					Main: true,
+				},
+				{
+					Prefix: "pkg2",
+					Tags:   []string{"db"},
+				},
+				{
+					Prefix: "pkg3",
+					Tags:   []string{"db", "cache"},
+				},
+				{
+					Prefix: "pkg4",
+				},
+				{
+					Prefix: "pkg5",
+					Tags:   []string{"db"},
+					Hidden: true,
+				},
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com Go Modules</title>
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+</style>
+</head>
+<body>
+<div class="content">
+
+<h2>example.com Go Modules</h2>
+
+<h3>Featured:</h3>
+
+<ul>
+<li>
+<a href="/pkg3">pkg3</a>
+</li>
+<li>
+<a href="/pkg1">pkg1</a> - Package one.
+<ul><li><a href="/pkg1/subpkg1">subpkg1</a> - Sub one.</li><li><a href="/pkg1/subpkg2">subpkg2</a> - Sub two.</li></ul></li>
+</ul>
+
+<h3>Databases:</h3>
+
+<ul>
+<li>
+<a href="/pkg1/subpkg2">pkg1/subpkg2</a> - Sub two.
+</li>
+<li>
+<a href="/pkg2">pkg2</a>
+</li>
+<li>
+<a href="/pkg3">pkg3</a>
+</li>
+</ul>
+
+<h3>Other:</h3>
+
+<ul>
+<li>
+<a href="/pkg4">pkg4</a>
+</li>
+</ul>
+
+<hr/>
+
+Generated by <a href="https://4d63.com/vangen">vangen</a>.
+
+</div>
+</body>
+</html>`,
+			expectedErr: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		err := generateIndex(&out, builtinTemplates[""].Index, Config{Domain: tc.domain, Sections: tc.sections, Repositories: tc.r}, "")
+		if err != tc.expectedErr {
+			t.Errorf("Test case %#v got err %#v, want %#v", tc, err, tc.expectedErr)
+		} else if out.String() != tc.expectedOut {
+			dmp := diffmatchpatch.New()
+			diffs := dmp.DiffMain(tc.expectedOut, out.String(), false)
+			t.Errorf("Test case %q got: \n%s\nAs diff:\n%s", tc.description, out.String(), dmp.DiffPrettyText(diffs))
+		}
+	}
+}
@@ -0,0 +1,223 @@
+package vanity
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// packageHTML is the built-in template for package pages. Its blocks can be
+// replaced by themes and by the templates passed to ParseTemplates.
+const packageHTML = `<!DOCTYPE html>
+<html>
+<head>
+{{block "head" .}}<meta charset="utf-8">
+<title>{{.Domain}}/{{.Package}}</title>
+{{end}}<meta name="go-import" content="{{.GoImport}}">
+<meta name="go-source" content="{{.GoSource}}">
+{{block "style" .}}<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+code { display: block; font-family: monospace; font-size: 1em; background-color: #d5d5d5; padding: 1em; margin-bottom: 16px; }
+ul { margin-top: 16px; margin-bottom: 16px; }
+</style>
+{{end}}</head>
+<body>
+<div class="content">
+{{block "header" .}}{{end}}{{block "package-body" .}}<h2>{{.Domain}}/{{.Package}}</h2>
+{{with .Description}}<p>{{.}}</p>
+{{end -}}
+<code>go get {{.Domain}}/{{.Package}}</code>
+<code>import "{{.Domain}}/{{.Package}}"</code>
+Home: <a href="{{.HomeURL}}">{{.HomeURL}}</a><br/>
+Source: <a href="{{.Repository.URL}}">{{.Repository.URL}}</a><br/>
+{{if .Repository.Subs -}}Sub-packages:<ul>{{end -}}
+{{range $i, $s := .Repository.Subs -}}{{if not $s.Hidden -}}<li><a href="{{$.BasePath}}/{{$.Repository.SubPath $i}}">{{$.Domain}}/{{$.Repository.SubPath $i}}</a>{{with $s.Description}} - {{.}}{{end}}</li>{{end -}}{{end -}}
+{{if .Repository.Subs -}}</ul>{{end -}}
+{{end}}{{block "footer" .}}{{end}}</div>
+</body>
+</html>`
+
+// PackageData is the data that package pages are rendered with.
+type PackageData struct {
+	// Domain is the vanity domain.
+	Domain string
+	// Package is the path of the package below the domain.
+	Package string
+	// BasePath is the path the pages are served below, such as /go, or
+	// empty if they are served at the root of the domain.
+	BasePath string
+	// Repository is the repository of the package, with its type, branch
+	// and source URLs filled in where they are inferred from its URL.
+	// Source URLs that are not known are "_".
+	Repository Repository
+	// HomeURL is the URL of the package's website, or its documentation if
+	// the repository has no website.
+	HomeURL string
+	// Description is the description of the sub the page is for, or of the
+	// repository if the page is for its prefix.
+	Description string
+	// GoImport and GoSource are the contents of the go-import and go-source
+	// meta tags.
+	GoImport string
+	GoSource string
+	// ImportPrefix, ImportType, ImportURL and ImportSubdir are the fields of
+	// the go-import meta tag, and SourcePrefix the first field of the
+	// go-source meta tag.
+	ImportPrefix string
+	ImportType   string
+	ImportURL    string
+	ImportSubdir string
+	SourcePrefix string
+}
+
+// generatePackage writes the page for pkg in repository r, rendered with
+// tmpl, linking to pages below basePath. The go-import and go-source meta
+// tags are added to the page if tmpl does not write them.
+func generatePackage(w io.Writer, tmpl *template.Template, c Config, basePath, pkg string, r Repository) error {
+	var homeURL string
+	if r.Website.URL != "" {
+		homeURL = r.Website.URL
+	} else {
+		docsDomain := c.DocsDomain
+		if docsDomain == "" {
+			docsDomain = "pkg.go.dev"
+		}
+		homeURL = fmt.Sprintf("https://%s/%s/%s", docsDomain, c.Domain, pkg)
+	}
+
+	if f, ok := c.forge(r.URL); ok {
+		if r.Type == "" {
+			r.Type = f.Type
+		}
+		if r.Branch == "" && c.Branch != BranchClone {
+			r.Branch = c.Branch
+		}
+		if r.Branch == "" || r.Branch == BranchClone {
+			r.Branch = defaultBranches[r.Type]
+		}
+		if inferred, ok := f.sourceURLs(r); ok {
+			if r.SourceURLs.Home == "" {
+				r.SourceURLs.Home = inferred.Home
+			}
+			if r.SourceURLs.Dir == "" {
+				r.SourceURLs.Dir = inferred.Dir
+			}
+			if r.SourceURLs.File == "" {
+				r.SourceURLs.File = inferred.File
+			}
+		}
+	}
+
+	// Repositories served by a module proxy are downloaded from the proxy,
+	// but their source is still browsed at their URL.
+	importType, importURL, importSubdir := r.Type, r.URL, r.Subdir
+	if proxy := c.proxy(r); proxy != "" {
+		importType, importURL, importSubdir = "mod", proxy, ""
+	}
+	if !contains(vcsTypes, importType) {
+		return fmt.Errorf("repository %q has unsupported type %q, must be one of %s", r.Prefix, importType, strings.Join(vcsTypes, ", "))
+	}
+
+	if r.SourceURLs.Home == "" {
+		r.SourceURLs.Home = "_"
+	}
+	if r.SourceURLs.Dir == "" {
+		r.SourceURLs.Dir = "_"
+	}
+	if r.SourceURLs.File == "" {
+		r.SourceURLs.File = "_"
+	}
+
+	var description string
+	if pkg == r.Prefix {
+		description = r.Description
+	}
+	for i, s := range r.Subs {
+		if r.SubPath(i) == pkg {
+			description = s.Description
+		}
+	}
+
+	data := PackageData{
+		Domain:       c.Domain,
+		Package:      pkg,
+		BasePath:     strings.TrimSuffix(basePath, "/"),
+		Repository:   r,
+		HomeURL:      homeURL,
+		Description:  description,
+		ImportPrefix: path.Join(c.Domain, r.goImportPrefix()),
+		ImportType:   importType,
+		ImportURL:    importURL,
+		ImportSubdir: importSubdir,
+		SourcePrefix: path.Join(c.Domain, r.goSourcePrefix()),
+	}
+	data.GoImport = strings.Join([]string{data.ImportPrefix, data.ImportType, data.ImportURL}, " ")
+	if data.ImportSubdir != "" {
+		data.GoImport += " " + data.ImportSubdir
+	}
+	data.GoSource = strings.Join([]string{data.SourcePrefix, r.SourceURLs.Home, r.SourceURLs.Dir, r.SourceURLs.File}, " ")
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, data)
+	if err != nil {
+		return fmt.Errorf("generating template: %v", err)
+	}
+
+	_, err = w.Write(ensureMetaTags(buf.Bytes(), data))
+	return err
+}
+
+// metaTagNames match a meta tag with the name go-import or go-source.
+var metaTagNames = map[string]*regexp.Regexp{
+	"go-import": regexp.MustCompile(`(?i)<meta\s[^>]*name\s*=\s*["']?go-import["'\s/>]`),
+	"go-source": regexp.MustCompile(`(?i)<meta\s[^>]*name\s*=\s*["']?go-source["'\s/>]`),
+}
+
+// headTags match the tags that meta tags missing from a page are added
+// after, in order of preference.
+var headTags = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)<head(\s[^>]*)?>`),
+	regexp.MustCompile(`(?i)<html(\s[^>]*)?>`),
+	regexp.MustCompile(`(?i)<!doctype[^>]*>`),
+}
+
+// ensureMetaTags returns page with the go-import and go-source meta tags of
+// data added to its head, if the template that rendered it left them out.
+// Without them the go command cannot find the repository of the package.
+func ensureMetaTags(page []byte, data PackageData) []byte {
+	var missing []string
+	for _, m := range []struct{ name, content string }{
+		{"go-import", data.GoImport},
+		{"go-source", data.GoSource},
+	} {
+		if !metaTagNames[m.name].Match(page) {
+			missing = append(missing, fmt.Sprintf(`<meta name="%s" content="%s">`, m.name, template.HTMLEscapeString(m.content)))
+		}
+	}
+	if len(missing) == 0 {
+		return page
+	}
+
+	var buf bytes.Buffer
+	for _, re := range headTags {
+		if loc := re.FindIndex(page); loc != nil {
+			buf.Write(page[:loc[1]])
+			for _, m := range missing {
+				buf.WriteString("\n" + m)
+			}
+			buf.Write(page[loc[1]:])
+			return buf.Bytes()
+		}
+	}
+	for _, m := range missing {
+		buf.WriteString(m + "\n")
+	}
+	buf.Write(page)
+	return buf.Bytes()
+}
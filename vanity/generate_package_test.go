@@ -1,4 +1,4 @@
-package main
+package vanity
 
 import (
 	"bytes"
@@ -12,8 +12,9 @@ func TestGenerate(t *testing.T) {
 		description string
 		domain      string
 		docsDomain  string
+		hosts       []Host
 		pkg         string
-		r           repository
+		r           Repository
 		expectedOut string
 		expectedErr error
 	}{
@@ -22,9 +23,9 @@ func TestGenerate(t *testing.T) {
 			domain:      "example.com",
 			docsDomain:  "godoc.org",
 			pkg:         "pkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				Type:   "git",
 				URL:    "https://repositoryhost.com/example/go-pkg1",
 			},
@@ -60,10 +61,10 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "godoc.org",
 			pkg:         "pkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
 				Hidden: true,
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				Type:   "git",
 				URL:    "https://repositoryhost.com/example/go-pkg1",
 			},
@@ -99,17 +100,17 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "pkg.go.dev",
 			pkg:         "pkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				Type:   "git",
 				URL:    "https://repositoryhost.com/example/go-pkg1",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://repositoryhost.com/example/go-pkg1/home",
 					Dir:  "https://repositoryhost.com/example/go-pkg1/browser{/dir}",
 					File: "https://repositoryhost.com/example/go-pkg1/view{/dir}{/file}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://www.example.com",
 				},
 			},
@@ -144,17 +145,17 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			description: "sub-package",
 			domain:      "example.com",
 			pkg:         "pkg1/subpkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				Type:   "git",
 				URL:    "https://repositoryhost.com/example/go-pkg1",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://repositoryhost.com/example/go-pkg1/home",
 					Dir:  "https://repositoryhost.com/example/go-pkg1/browser{/dir}",
 					File: "https://repositoryhost.com/example/go-pkg1/view{/dir}{/file}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://www.example.com",
 				},
 			},
@@ -182,6 +183,45 @@ Home: <a href="https://www.example.com">https://www.example.com</a><br/>
 Source: <a href="https://repositoryhost.com/example/go-pkg1">https://repositoryhost.com/example/go-pkg1</a><br/>
 Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><li><a href="/pkg1/subpkg2">example.com/pkg1/subpkg2</a></li></ul></div>
 </body>
+</html>`,
+			expectedErr: nil,
+		},
+		{
+			description: "descriptions",
+			domain:      "example.com",
+			pkg:         "pkg1/subpkg1",
+			r: Repository{
+				Prefix:      "pkg1",
+				Description: "Package one.",
+				Subs:        []Sub{{Name: "subpkg1", Description: "Sub one."}, {Name: "subpkg2", Description: "Sub <two>."}},
+				Type:        "git",
+				URL:         "https://repositoryhost.com/example/go-pkg1",
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com/pkg1/subpkg1</title>
+<meta name="go-import" content="example.com/pkg1 git https://repositoryhost.com/example/go-pkg1">
+<meta name="go-source" content="example.com/pkg1 _ _ _">
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+code { display: block; font-family: monospace; font-size: 1em; background-color: #d5d5d5; padding: 1em; margin-bottom: 16px; }
+ul { margin-top: 16px; margin-bottom: 16px; }
+</style>
+</head>
+<body>
+<div class="content">
+<h2>example.com/pkg1/subpkg1</h2>
+<p>Sub one.</p>
+<code>go get example.com/pkg1/subpkg1</code>
+<code>import "example.com/pkg1/subpkg1"</code>
+Home: <a href="https://pkg.go.dev/example.com/pkg1/subpkg1">https://pkg.go.dev/example.com/pkg1/subpkg1</a><br/>
+Source: <a href="https://repositoryhost.com/example/go-pkg1">https://repositoryhost.com/example/go-pkg1</a><br/>
+Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a> - Sub one.</li><li><a href="/pkg1/subpkg2">example.com/pkg1/subpkg2</a> - Sub &lt;two&gt;.</li></ul></div>
+</body>
 </html>`,
 			expectedErr: nil,
 		},
@@ -189,17 +229,17 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			description: "sub-package hidden",
 			domain:      "example.com",
 			pkg:         "pkg1/subpkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}, {Name: "subpkg3", Hidden: true}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}, {Name: "subpkg3", Hidden: true}},
 				Type:   "git",
 				URL:    "https://repositoryhost.com/example/go-pkg1",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://repositoryhost.com/example/go-pkg1/home",
 					Dir:  "https://repositoryhost.com/example/go-pkg1/browser{/dir}",
 					File: "https://repositoryhost.com/example/go-pkg1/view{/dir}{/file}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://www.example.com",
 				},
 			},
@@ -235,9 +275,9 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "pkg.go.dev",
 			pkg:         "pkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				URL:    "https://github.com/example/go-pkg1",
 			},
 			expectedOut: `<!DOCTYPE html>
@@ -264,6 +304,44 @@ Home: <a href="https://pkg.go.dev/example.com/pkg1">https://pkg.go.dev/example.c
 Source: <a href="https://github.com/example/go-pkg1">https://github.com/example/go-pkg1</a><br/>
 Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><li><a href="/pkg1/subpkg2">example.com/pkg1/subpkg2</a></li></ul></div>
 </body>
+</html>`,
+			expectedErr: nil,
+		},
+		{
+			description: "github defaults with branch",
+			domain:      "example.com",
+			docsDomain:  "pkg.go.dev",
+			pkg:         "pkg1",
+			r: Repository{
+				Prefix: "pkg1",
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				URL:    "https://github.com/example/go-pkg1",
+				Branch: "main",
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com/pkg1</title>
+<meta name="go-import" content="example.com/pkg1 git https://github.com/example/go-pkg1">
+<meta name="go-source" content="example.com/pkg1 https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/main{/dir} https://github.com/example/go-pkg1/blob/main{/dir}/{file}#L{line}">
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+code { display: block; font-family: monospace; font-size: 1em; background-color: #d5d5d5; padding: 1em; margin-bottom: 16px; }
+ul { margin-top: 16px; margin-bottom: 16px; }
+</style>
+</head>
+<body>
+<div class="content">
+<h2>example.com/pkg1</h2>
+<code>go get example.com/pkg1</code>
+<code>import "example.com/pkg1"</code>
+Home: <a href="https://pkg.go.dev/example.com/pkg1">https://pkg.go.dev/example.com/pkg1</a><br/>
+Source: <a href="https://github.com/example/go-pkg1">https://github.com/example/go-pkg1</a><br/>
+Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><li><a href="/pkg1/subpkg2">example.com/pkg1/subpkg2</a></li></ul></div>
+</body>
 </html>`,
 			expectedErr: nil,
 		},
@@ -272,9 +350,9 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "pkg.go.dev",
 			pkg:         "pkg1/subpkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				URL:    "https://github.com/example/go-pkg1",
 			},
 			expectedOut: `<!DOCTYPE html>
@@ -309,9 +387,9 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "",
 			pkg:         "pkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				URL:    "https://gitlab.com/example/go-pkg1",
 			},
 			expectedOut: `<!DOCTYPE html>
@@ -320,7 +398,7 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 <meta charset="utf-8">
 <title>example.com/pkg1</title>
 <meta name="go-import" content="example.com/pkg1 git https://gitlab.com/example/go-pkg1">
-<meta name="go-source" content="example.com/pkg1 https://gitlab.com/example/go-pkg1 https://gitlab.com/example/go-pkg1/tree/master{/dir} https://gitlab.com/example/go-pkg1/blob/master{/dir}/{file}#L{line}">
+<meta name="go-source" content="example.com/pkg1 https://gitlab.com/example/go-pkg1 https://gitlab.com/example/go-pkg1/-/tree/master{/dir} https://gitlab.com/example/go-pkg1/-/blob/master{/dir}/{file}#L{line}">
 <style>
 * { font-family: sans-serif; }
 body { margin-top: 0; }
@@ -338,6 +416,43 @@ Home: <a href="https://pkg.go.dev/example.com/pkg1">https://pkg.go.dev/example.c
 Source: <a href="https://gitlab.com/example/go-pkg1">https://gitlab.com/example/go-pkg1</a><br/>
 Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><li><a href="/pkg1/subpkg2">example.com/pkg1/subpkg2</a></li></ul></div>
 </body>
+</html>`,
+			expectedErr: nil,
+		},
+		{
+			description: "configured gitea host",
+			domain:      "example.com",
+			docsDomain:  "pkg.go.dev",
+			hosts:       []Host{{Host: "git.example.com", Kind: "gitea"}},
+			pkg:         "pkg1",
+			r: Repository{
+				Prefix: "pkg1",
+				URL:    "https://git.example.com/example/go-pkg1",
+			},
+			expectedOut: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>example.com/pkg1</title>
+<meta name="go-import" content="example.com/pkg1 git https://git.example.com/example/go-pkg1">
+<meta name="go-source" content="example.com/pkg1 https://git.example.com/example/go-pkg1 https://git.example.com/example/go-pkg1/src/branch/master{/dir} https://git.example.com/example/go-pkg1/src/branch/master{/dir}/{file}#L{line}">
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; }
+.content { display: inline-block; }
+code { display: block; font-family: monospace; font-size: 1em; background-color: #d5d5d5; padding: 1em; margin-bottom: 16px; }
+ul { margin-top: 16px; margin-bottom: 16px; }
+</style>
+</head>
+<body>
+<div class="content">
+<h2>example.com/pkg1</h2>
+<code>go get example.com/pkg1</code>
+<code>import "example.com/pkg1"</code>
+Home: <a href="https://pkg.go.dev/example.com/pkg1">https://pkg.go.dev/example.com/pkg1</a><br/>
+Source: <a href="https://git.example.com/example/go-pkg1">https://git.example.com/example/go-pkg1</a><br/>
+</div>
+</body>
 </html>`,
 			expectedErr: nil,
 		},
@@ -346,9 +461,9 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "pkg.go.dev",
 			pkg:         "pkg1/subpkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				URL:    "https://gitlab.com/example/go-pkg1",
 			},
 			expectedOut: `<!DOCTYPE html>
@@ -357,7 +472,7 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 <meta charset="utf-8">
 <title>example.com/pkg1/subpkg1</title>
 <meta name="go-import" content="example.com/pkg1 git https://gitlab.com/example/go-pkg1">
-<meta name="go-source" content="example.com/pkg1 https://gitlab.com/example/go-pkg1 https://gitlab.com/example/go-pkg1/tree/master{/dir} https://gitlab.com/example/go-pkg1/blob/master{/dir}/{file}#L{line}">
+<meta name="go-source" content="example.com/pkg1 https://gitlab.com/example/go-pkg1 https://gitlab.com/example/go-pkg1/-/tree/master{/dir} https://gitlab.com/example/go-pkg1/-/blob/master{/dir}/{file}#L{line}">
 <style>
 * { font-family: sans-serif; }
 body { margin-top: 0; }
@@ -383,17 +498,17 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "",
 			pkg:         "pkg1",
-			r: repository{
+			r: Repository{
 				Prefix: "pkg1",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				Type:   "git",
 				URL:    "https://github.com/example/go-pkg1",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://github.com/example/go-pkg1",
 					Dir:  "https://github.com/example/go-pkg1/tree/branch{/dir}",
 					File: "https://github.com/example/go-pkg1/blob/branch{/dir}/{file}#L{line}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://www.example.com",
 				},
 			},
@@ -429,17 +544,17 @@ Sub-packages:<ul><li><a href="/pkg1/subpkg1">example.com/pkg1/subpkg1</a></li><l
 			domain:      "example.com",
 			docsDomain:  "",
 			pkg:         "",
-			r: repository{
+			r: Repository{
 				Prefix: "",
-				Subs:   []sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2"}},
 				Type:   "git",
 				URL:    "https://github.com/example/go-pkg1",
-				SourceURLs: sourceURLs{
+				SourceURLs: SourceURLs{
 					Home: "https://github.com/example/go-pkg1",
 					Dir:  "https://github.com/example/go-pkg1/tree/branch{/dir}",
 					File: "https://github.com/example/go-pkg1/blob/branch{/dir}/{file}#L{line}",
 				},
-				Website: website{
+				Website: Website{
 					URL: "https://www.example.com",
 				},
 			},
@@ -474,7 +589,7 @@ Sub-packages:<ul><li><a href="/subpkg1">example.com/subpkg1</a></li><li><a href=
 
 	for _, tc := range testCases {
 		var out bytes.Buffer
-		err := generate_package(&out, tc.domain, tc.docsDomain, tc.pkg, tc.r)
+		err := generatePackage(&out, builtinTemplates[""].Package, Config{Domain: tc.domain, DocsDomain: tc.docsDomain, Hosts: tc.hosts}, "", tc.pkg, tc.r)
 		if err != tc.expectedErr {
 			t.Errorf("Test case %q got err %#v, want %#v", tc.description, err, tc.expectedErr)
 		} else if out.String() != tc.expectedOut {
@@ -0,0 +1,114 @@
+// Package vanity generates the HTML pages that serve Go vanity import paths,
+// either as static files or from an http.Handler.
+package vanity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// ErrNotFound is returned when a package does not belong to any repository in
+// the config and is not the index.
+var ErrNotFound = errors.New("vanity: package not found")
+
+// Generator renders the index and package pages for the repositories in a
+// Config.
+type Generator struct {
+	Config Config
+	// Templates replace the built-in templates that pages are rendered
+	// with.
+	Templates Templates
+	// Assets are written alongside the pages.
+	Assets *Assets
+	// BasePath is the path the pages are served below, such as /go when a
+	// Handler is mounted at /go/ with http.StripPrefix. Links between pages
+	// start with it. It is empty for pages served at the root of the domain.
+	BasePath string
+}
+
+// NewGenerator returns a Generator for the config. Configs are not
+// validated, and a Theme that is not a built-in theme is rendered with the
+// plain theme. Use Validate to report it.
+func NewGenerator(c Config) *Generator {
+	return &Generator{Config: c}
+}
+
+// Files returns the slash separated paths of the files that FS contains.
+func (g *Generator) Files() []string {
+	files := []string{}
+	seen := map[string]bool{}
+	add := func(pkg string) {
+		name := packageFile(pkg)
+		if seen[name] {
+			return
+		}
+		seen[name] = true
+		files = append(files, name)
+	}
+	if g.Config.Index {
+		add("")
+	}
+	for _, r := range g.Config.allRepositories() {
+		for _, p := range r.Packages() {
+			add(p)
+		}
+	}
+	return append(files, g.Assets.Files()...)
+}
+
+// WriteIndex writes the index page listing all repositories.
+func (g *Generator) WriteIndex(w io.Writer) error {
+	return generateIndex(w, g.Templates.indexTemplate(g.Config.Theme), g.Config, g.BasePath)
+}
+
+// WritePackage writes the page for pkg, which may be any package at or below
+// a repository prefix. If pkg is empty and no repository has an empty prefix
+// the index is written if it is enabled. ErrNotFound is returned if there is
+// no page for pkg.
+func (g *Generator) WritePackage(w io.Writer, pkg string) error {
+	r, ok := g.Config.repositoryForPackage(pkg)
+	if ok {
+		return generatePackage(w, g.Templates.packageTemplate(g.Config.Theme), g.Config, g.BasePath, pkg, r)
+	}
+	if pkg == "" && g.Config.Index {
+		return g.WriteIndex(w)
+	}
+	return ErrNotFound
+}
+
+// FS renders every file returned by Files and returns them as a file system.
+func (g *Generator) FS() (fs.FS, error) {
+	files := mapFS{}
+	for _, name := range g.Files() {
+		if !fs.ValidPath(name) {
+			return nil, fmt.Errorf("generating %s: path is outside the output directory", name)
+		}
+		if data, ok := g.Assets.file(name); ok {
+			files[name] = data
+			continue
+		}
+		var buf bytes.Buffer
+		err := g.WritePackage(&buf, filePackage(name))
+		if err != nil {
+			return nil, fmt.Errorf("generating %s: %w", name, err)
+		}
+		files[name] = buf.Bytes()
+	}
+	return files, nil
+}
+
+// packageFile returns the path of the file that the page for pkg is written
+// to.
+func packageFile(pkg string) string {
+	return path.Join(pkg, "index.html")
+}
+
+// filePackage returns the package that the file at name is the page for.
+func filePackage(name string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(name, "index.html"), "/")
+}
@@ -0,0 +1,403 @@
+package vanity
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGeneratorFiles(t *testing.T) {
+	testCases := []struct {
+		description   string
+		c             Config
+		expectedFiles []string
+	}{
+		{
+			description: "no index",
+			c: Config{
+				Domain: "example.com",
+				Repositories: []Repository{
+					{Prefix: "pkg1", Subs: []Sub{{Name: "subpkg1"}, {Name: "subpkg2/subsubpkg1", Hidden: true}}},
+					{Prefix: "pkg2"},
+				},
+			},
+			expectedFiles: []string{
+				"pkg1/index.html",
+				"pkg1/subpkg1/index.html",
+				"pkg1/subpkg2/subsubpkg1/index.html",
+				"pkg2/index.html",
+			},
+		},
+		{
+			description: "index",
+			c: Config{
+				Domain: "example.com",
+				Index:  true,
+				Repositories: []Repository{
+					{Prefix: "pkg1"},
+				},
+			},
+			expectedFiles: []string{
+				"index.html",
+				"pkg1/index.html",
+			},
+		},
+		{
+			description: "index replaced by repository without prefix",
+			c: Config{
+				Domain: "example.com",
+				Index:  true,
+				Repositories: []Repository{
+					{Prefix: "", Subs: []Sub{{Name: "subpkg1"}}},
+				},
+			},
+			expectedFiles: []string{
+				"index.html",
+				"subpkg1/index.html",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		files := NewGenerator(tc.c).Files()
+		if !reflect.DeepEqual(files, tc.expectedFiles) {
+			t.Errorf("Test case %q got files %#v, want %#v", tc.description, files, tc.expectedFiles)
+		}
+	}
+}
+
+func TestGeneratorFS(t *testing.T) {
+	c := Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []Repository{
+			{
+				Prefix: "pkg1",
+				Subs:   []Sub{{Name: "subpkg1"}, {Name: "subpkg2/subsubpkg1"}},
+				URL:    "https://github.com/example/go-pkg1",
+			},
+			{
+				Prefix: "pkg2",
+				URL:    "https://github.com/example/go-pkg2",
+			},
+		},
+	}
+	g := NewGenerator(c)
+
+	files, err := g.FS()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fstest.TestFS(files, g.Files()...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var expectedIndex bytes.Buffer
+	err = g.WriteIndex(&expectedIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index, err := fs.ReadFile(files, "index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(index), expectedIndex.String(); g != w {
+		t.Errorf("Got index.html %q, want %q", g, w)
+	}
+
+	var expectedPackage bytes.Buffer
+	err = generatePackage(&expectedPackage, builtinTemplates[""].Package, c, "", "pkg1/subpkg2/subsubpkg1", c.Repositories[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg, err := fs.ReadFile(files, "pkg1/subpkg2/subsubpkg1/index.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := string(pkg), expectedPackage.String(); g != w {
+		t.Errorf("Got pkg1/subpkg2/subsubpkg1/index.html %q, want %q", g, w)
+	}
+}
+
+func TestGeneratorWritePackageNotFound(t *testing.T) {
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{Prefix: "pkg1"},
+		},
+	})
+
+	for _, pkg := range []string{"", "pkg", "pkg2", "pkg2/pkg1"} {
+		err := g.WritePackage(io.Discard, pkg)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Package %q got err %#v, want %#v", pkg, err, ErrNotFound)
+		}
+	}
+}
+
+func TestGeneratorBranch(t *testing.T) {
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Branch: "main",
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/go-pkg1"},
+			{Prefix: "pkg2", URL: "https://github.com/example/go-pkg2", Branch: "trunk"},
+		},
+	})
+
+	testCases := []struct {
+		pkg            string
+		expectedSource string
+	}{
+		{
+			pkg:            "pkg1",
+			expectedSource: `<meta name="go-source" content="example.com/pkg1 https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/main{/dir} https://github.com/example/go-pkg1/blob/main{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg2",
+			expectedSource: `<meta name="go-source" content="example.com/pkg2 https://github.com/example/go-pkg2 https://github.com/example/go-pkg2/tree/trunk{/dir} https://github.com/example/go-pkg2/blob/trunk{/dir}/{file}#L{line}">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		err := g.WritePackage(&out, tc.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), tc.expectedSource) {
+			t.Errorf("Package %q got:\n%s\nwant it to contain:\n%s", tc.pkg, out.String(), tc.expectedSource)
+		}
+	}
+}
+
+func TestGeneratorVCSTypes(t *testing.T) {
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://hg.sr.ht/~example/go-pkg1"},
+			{Prefix: "pkg2", URL: "https://bitbucket.org/example/go-pkg2", Type: "hg"},
+			{Prefix: "pkg3", URL: "https://github.com/example/go-pkg3", Type: "svn"},
+			{Prefix: "pkg4", URL: "https://example.com/go-pkg4", Type: "fossil"},
+			{Prefix: "pkg5", URL: "https://example.com/go-pkg5", Type: "cvs"},
+		},
+	})
+
+	testCases := []struct {
+		pkg            string
+		expectedImport string
+		expectedSource string
+		expectedErr    string
+	}{
+		{
+			pkg:            "pkg1",
+			expectedImport: `<meta name="go-import" content="example.com/pkg1 hg https://hg.sr.ht/~example/go-pkg1">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg1 https://hg.sr.ht/~example/go-pkg1 https://hg.sr.ht/~example/go-pkg1/browse{/dir}?rev=default https://hg.sr.ht/~example/go-pkg1/browse{/dir}/{file}?rev=default#L{line}">`,
+		},
+		{
+			pkg:            "pkg2",
+			expectedImport: `<meta name="go-import" content="example.com/pkg2 hg https://bitbucket.org/example/go-pkg2">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg2 https://bitbucket.org/example/go-pkg2 https://bitbucket.org/example/go-pkg2/src/default{/dir} https://bitbucket.org/example/go-pkg2/src/default{/dir}/{file}#lines-{line}">`,
+		},
+		{
+			pkg:            "pkg3",
+			expectedImport: `<meta name="go-import" content="example.com/pkg3 svn https://github.com/example/go-pkg3">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg3 _ _ _">`,
+		},
+		{
+			pkg:            "pkg4",
+			expectedImport: `<meta name="go-import" content="example.com/pkg4 fossil https://example.com/go-pkg4">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg4 _ _ _">`,
+		},
+		{
+			pkg:         "pkg5",
+			expectedErr: `repository "pkg5" has unsupported type "cvs", must be one of git, hg, svn, bzr, fossil, mod`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		err := g.WritePackage(&out, tc.pkg)
+		if tc.expectedErr != "" {
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("Package %q got err %v, want %s", tc.pkg, err, tc.expectedErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{tc.expectedImport, tc.expectedSource} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Package %q got:\n%s\nwant it to contain:\n%s", tc.pkg, out.String(), want)
+			}
+		}
+	}
+}
+
+func TestGeneratorProxy(t *testing.T) {
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Proxy:  "https://athens.example.com",
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/go-pkg1"},
+			{Prefix: "pkg2", URL: "https://github.com/example/go-pkg2", Proxy: "https://proxy.example.com"},
+			{Prefix: "pkg3", URL: "https://github.com/example/go-pkg3", Proxy: "direct"},
+			{Prefix: "pkg4", URL: "https://example.com/go-pkg4"},
+		},
+	})
+
+	testCases := []struct {
+		pkg            string
+		expectedImport string
+		expectedSource string
+	}{
+		{
+			pkg:            "pkg1",
+			expectedImport: `<meta name="go-import" content="example.com/pkg1 mod https://athens.example.com">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg1 https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/master{/dir} https://github.com/example/go-pkg1/blob/master{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg2",
+			expectedImport: `<meta name="go-import" content="example.com/pkg2 mod https://proxy.example.com">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg2 https://github.com/example/go-pkg2 https://github.com/example/go-pkg2/tree/master{/dir} https://github.com/example/go-pkg2/blob/master{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg3",
+			expectedImport: `<meta name="go-import" content="example.com/pkg3 git https://github.com/example/go-pkg3">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg3 https://github.com/example/go-pkg3 https://github.com/example/go-pkg3/tree/master{/dir} https://github.com/example/go-pkg3/blob/master{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg4",
+			expectedImport: `<meta name="go-import" content="example.com/pkg4 mod https://athens.example.com">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg4 _ _ _">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		err := g.WritePackage(&out, tc.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{tc.expectedImport, tc.expectedSource} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Package %q got:\n%s\nwant it to contain:\n%s", tc.pkg, out.String(), want)
+			}
+		}
+	}
+}
+
+func TestGeneratorSubdir(t *testing.T) {
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/monorepo", Subdir: "go/pkg1"},
+			{Prefix: "pkg2", URL: "https://github.com/example/monorepo", Subdir: "go/pkg2", Proxy: "https://proxy.example.com"},
+		},
+	})
+
+	testCases := []struct {
+		pkg            string
+		expectedImport string
+		expectedSource string
+	}{
+		{
+			pkg:            "pkg1",
+			expectedImport: `<meta name="go-import" content="example.com/pkg1 git https://github.com/example/monorepo go/pkg1">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg1 https://github.com/example/monorepo https://github.com/example/monorepo/tree/master/go/pkg1{/dir} https://github.com/example/monorepo/blob/master/go/pkg1{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg2",
+			expectedImport: `<meta name="go-import" content="example.com/pkg2 mod https://proxy.example.com">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg2 https://github.com/example/monorepo https://github.com/example/monorepo/tree/master/go/pkg2{/dir} https://github.com/example/monorepo/blob/master/go/pkg2{/dir}/{file}#L{line}">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		err := g.WritePackage(&out, tc.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{tc.expectedImport, tc.expectedSource} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Package %q got:\n%s\nwant it to contain:\n%s", tc.pkg, out.String(), want)
+			}
+		}
+	}
+}
+
+func TestGeneratorVersions(t *testing.T) {
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{
+				Prefix: "pkg1",
+				Subs:   []Sub{{Name: "subpkg1"}},
+				URL:    "https://github.com/example/go-pkg1",
+				Versions: []Version{
+					{Version: "v2"},
+					{Version: "v3", Branch: "v3"},
+					{Version: "v4", URL: "https://github.com/example/go-pkg1-v4"},
+				},
+			},
+		},
+	})
+
+	expectedFiles := []string{
+		"pkg1/index.html",
+		"pkg1/subpkg1/index.html",
+		"pkg1/v2/index.html",
+		"pkg1/v2/subpkg1/index.html",
+		"pkg1/v3/index.html",
+		"pkg1/v3/subpkg1/index.html",
+		"pkg1/v4/index.html",
+		"pkg1/v4/subpkg1/index.html",
+	}
+	if g, w := g.Files(), expectedFiles; !reflect.DeepEqual(g, w) {
+		t.Errorf("Got files %q, want %q", g, w)
+	}
+
+	testCases := []struct {
+		pkg            string
+		expectedImport string
+		expectedSource string
+	}{
+		{
+			pkg:            "pkg1/v2/subpkg1",
+			expectedImport: `<meta name="go-import" content="example.com/pkg1 git https://github.com/example/go-pkg1">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg1 https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/master{/dir} https://github.com/example/go-pkg1/blob/master{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg1/v3",
+			expectedImport: `<meta name="go-import" content="example.com/pkg1 git https://github.com/example/go-pkg1">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg1/v3 https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/v3{/dir} https://github.com/example/go-pkg1/blob/v3{/dir}/{file}#L{line}">`,
+		},
+		{
+			pkg:            "pkg1/v4/subpkg1",
+			expectedImport: `<meta name="go-import" content="example.com/pkg1/v4 git https://github.com/example/go-pkg1-v4">`,
+			expectedSource: `<meta name="go-source" content="example.com/pkg1/v4 https://github.com/example/go-pkg1-v4 https://github.com/example/go-pkg1-v4/tree/master{/dir} https://github.com/example/go-pkg1-v4/blob/master{/dir}/{file}#L{line}">`,
+		},
+	}
+
+	for _, tc := range testCases {
+		var out bytes.Buffer
+		err := g.WritePackage(&out, tc.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{tc.expectedImport, tc.expectedSource, `<code>go get example.com/` + tc.pkg + `</code>`} {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("Package %q got:\n%s\nwant it to contain:\n%s", tc.pkg, out.String(), want)
+			}
+		}
+	}
+}
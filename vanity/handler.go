@@ -0,0 +1,76 @@
+package vanity
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync/atomic"
+)
+
+// Handler serves the pages rendered by a Generator, rendering them on each
+// request, and the Generator's assets. Any package below a repository prefix
+// is served, not only the packages listed as subs, and paths that belong to
+// no repository are not found.
+//
+// A Handler can be mounted below a path on an existing server with
+// http.StripPrefix, with the Generator's BasePath set to the path so that
+// pages link to each other below it.
+type Handler struct {
+	generator atomic.Pointer[Generator]
+}
+
+// NewHandler returns a Handler serving the pages rendered by g.
+func NewHandler(g *Generator) *Handler {
+	h := &Handler{}
+	h.generator.Store(g)
+	return h
+}
+
+// SetGenerator replaces the Generator used to render pages. Requests already
+// being served complete with the previous Generator. It is safe to call
+// while requests are being served.
+func (h *Handler) SetGenerator(g *Generator) {
+	h.generator.Store(g)
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	pkg := strings.Trim(path.Clean("/"+r.URL.Path), "/")
+	g := h.generator.Load()
+
+	if data, ok := g.Assets.file(pkg); ok {
+		ctype := mime.TypeByExtension(path.Ext(pkg))
+		if ctype == "" {
+			ctype = http.DetectContentType(data)
+		}
+		w.Header().Set("Content-Type", ctype)
+		// Assets have the hash of their contents in their name, so the
+		// contents at a name never change.
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Write(data)
+		return
+	}
+
+	var buf bytes.Buffer
+	err := g.WritePackage(&buf, pkg)
+	if errors.Is(err, ErrNotFound) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		log.Printf("vanity: generating package %q: %v", pkg, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(buf.Bytes())
+}
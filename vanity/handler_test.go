@@ -0,0 +1,160 @@
+package vanity
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+func TestHandler(t *testing.T) {
+	c := Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []Repository{
+			{
+				Prefix: "pkg1",
+				Subs:   []Sub{{Name: "subpkg1"}},
+				URL:    "https://github.com/example/go-pkg1",
+			},
+			{
+				Prefix: "pkg1/sub",
+				URL:    "https://github.com/example/go-pkg1-sub",
+			},
+			{
+				Prefix: "pkg2",
+				Hidden: true,
+				URL:    "https://github.com/example/go-pkg2",
+			},
+		},
+	}
+
+	testCases := []struct {
+		description    string
+		method         string
+		target         string
+		expectedStatus int
+		expectedPkg    string
+		expectedRepo   int
+		expectedIndex  bool
+	}{
+		{description: "index", method: "GET", target: "/", expectedStatus: 200, expectedIndex: true},
+		{description: "prefix", method: "GET", target: "/pkg1", expectedStatus: 200, expectedPkg: "pkg1", expectedRepo: 0},
+		{description: "go-get", method: "GET", target: "/pkg1?go-get=1", expectedStatus: 200, expectedPkg: "pkg1", expectedRepo: 0},
+		{description: "trailing slash", method: "GET", target: "/pkg1/", expectedStatus: 200, expectedPkg: "pkg1", expectedRepo: 0},
+		{description: "listed sub", method: "GET", target: "/pkg1/subpkg1?go-get=1", expectedStatus: 200, expectedPkg: "pkg1/subpkg1", expectedRepo: 0},
+		{description: "unlisted deep sub", method: "GET", target: "/pkg1/a/b/c?go-get=1", expectedStatus: 200, expectedPkg: "pkg1/a/b/c", expectedRepo: 0},
+		{description: "longest prefix", method: "GET", target: "/pkg1/sub/a", expectedStatus: 200, expectedPkg: "pkg1/sub/a", expectedRepo: 1},
+		{description: "hidden", method: "GET", target: "/pkg2", expectedStatus: 200, expectedPkg: "pkg2", expectedRepo: 2},
+		{description: "head", method: "HEAD", target: "/pkg2", expectedStatus: 200, expectedPkg: "pkg2", expectedRepo: 2},
+		{description: "unknown", method: "GET", target: "/pkg3?go-get=1", expectedStatus: 404},
+		{description: "prefix of prefix", method: "GET", target: "/pkg", expectedStatus: 404},
+		{description: "post", method: "POST", target: "/pkg1", expectedStatus: 405},
+	}
+
+	s := NewHandler(NewGenerator(c))
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+
+		if rec.Code != tc.expectedStatus {
+			t.Errorf("Test case %q got status %d, want %d", tc.description, rec.Code, tc.expectedStatus)
+			continue
+		}
+		if tc.expectedStatus != http.StatusOK {
+			continue
+		}
+
+		var expectedOut bytes.Buffer
+		if tc.expectedIndex {
+			err := generateIndex(&expectedOut, builtinTemplates[""].Index, c, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			err := generatePackage(&expectedOut, builtinTemplates[""].Package, c, "", tc.expectedPkg, c.Repositories[tc.expectedRepo])
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if rec.Body.String() != expectedOut.String() {
+			dmp := diffmatchpatch.New()
+			diffs := dmp.DiffMain(expectedOut.String(), rec.Body.String(), false)
+			t.Errorf("Test case %q got: \n%s\nAs diff:\n%s", tc.description, rec.Body.String(), dmp.DiffPrettyText(diffs))
+		}
+	}
+}
+
+func TestHandlerNoIndex(t *testing.T) {
+	s := NewHandler(NewGenerator(Config{Domain: "example.com"}))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if g, w := rec.Code, http.StatusNotFound; g != w {
+		t.Errorf("Got status %d, want %d", g, w)
+	}
+}
+
+func TestHandlerNoPrefix(t *testing.T) {
+	c := Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []Repository{
+			{URL: "https://github.com/example/go-pkg1"},
+		},
+	}
+	s := NewHandler(NewGenerator(c))
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+
+	var expectedOut bytes.Buffer
+	err := generatePackage(&expectedOut, builtinTemplates[""].Package, c, "", "", c.Repositories[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := rec.Body.String(), expectedOut.String(); g != w {
+		t.Errorf("Got body %q, want %q", g, w)
+	}
+}
+
+func TestHandlerBasePath(t *testing.T) {
+	c := Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []Repository{
+			{
+				Prefix:   "pkg1",
+				Subs:     []Sub{{Name: "subpkg1"}},
+				URL:      "https://github.com/example/go-pkg1",
+				Versions: []Version{{Version: "v2"}},
+			},
+		},
+	}
+	g := NewGenerator(c)
+	g.BasePath = "/go/"
+	mux := http.NewServeMux()
+	mux.Handle("/go/", http.StripPrefix("/go", NewHandler(g)))
+
+	testCases := []struct {
+		path          string
+		expectedLinks []string
+	}{
+		{path: "/go/", expectedLinks: []string{`href="/go/pkg1"`, `href="/go/pkg1/v2"`, `href="/go/pkg1/subpkg1"`}},
+		{path: "/go/pkg1", expectedLinks: []string{`href="/go/pkg1/subpkg1"`}},
+	}
+	for _, tc := range testCases {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest("GET", tc.path, nil))
+		if g, w := rec.Code, 200; g != w {
+			t.Errorf("Path %q got status %d, want %d", tc.path, g, w)
+			continue
+		}
+		for _, link := range tc.expectedLinks {
+			if !strings.Contains(rec.Body.String(), link) {
+				t.Errorf("Path %q got body:\n%s\nwant it to contain %s", tc.path, rec.Body.String(), link)
+			}
+		}
+	}
+}
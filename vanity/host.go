@@ -0,0 +1,178 @@
+package vanity
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// A Forge is a kind of repository host, such as GitHub or Gitea, whose URLs
+// for browsing source follow a known layout.
+type Forge struct {
+	// Type is the VCS type of repositories on the forge, used for
+	// repositories that have no type.
+	Type string
+	// Source are the templates for the go-source URLs of repositories on the
+	// forge, keyed by the VCS type of the repository. Source URLs are not
+	// inferred for repositories of other types. In each template {url} is
+	// replaced with the repository URL, without any .git suffix, {branch}
+	// with its branch, and {/subdir} with a slash and the repository's
+	// subdir, or nothing if it has none. The other placeholders, such as
+	// {/dir}, {file} and {line}, are left for the go command and
+	// documentation sites to expand.
+	Source map[string]SourceURLs
+}
+
+var (
+	githubSource = SourceURLs{
+		Home: "{url}",
+		Dir:  "{url}/tree/{branch}{/subdir}{/dir}",
+		File: "{url}/blob/{branch}{/subdir}{/dir}/{file}#L{line}",
+	}
+	// The /-/ separates the project path, which can contain any number of
+	// nested subgroups, from the route.
+	gitlabSource = SourceURLs{
+		Home: "{url}",
+		Dir:  "{url}/-/tree/{branch}{/subdir}{/dir}",
+		File: "{url}/-/blob/{branch}{/subdir}{/dir}/{file}#L{line}",
+	}
+	bitbucketSource = SourceURLs{
+		Home: "{url}",
+		Dir:  "{url}/src/{branch}{/subdir}{/dir}",
+		File: "{url}/src/{branch}{/subdir}{/dir}/{file}#lines-{line}",
+	}
+	giteaSource = SourceURLs{
+		Home: "{url}",
+		Dir:  "{url}/src/branch/{branch}{/subdir}{/dir}",
+		File: "{url}/src/branch/{branch}{/subdir}{/dir}/{file}#L{line}",
+	}
+)
+
+// Forges are the kinds of forge that source URLs can be inferred for, keyed
+// by kind. Programs using the package can add kinds before generating pages,
+// and hosts in a config refer to them by kind.
+var Forges = map[string]Forge{
+	"github": {
+		Type:   "git",
+		Source: map[string]SourceURLs{"git": githubSource},
+	},
+	"gitlab": {
+		Type:   "git",
+		Source: map[string]SourceURLs{"git": gitlabSource},
+	},
+	"bitbucket": {
+		// Bitbucket Cloud no longer hosts Mercurial repositories, but
+		// Bitbucket Server instances and mirrors of them still do.
+		Type:   "git",
+		Source: map[string]SourceURLs{"git": bitbucketSource, "hg": bitbucketSource},
+	},
+	"gitea": {
+		Type:   "git",
+		Source: map[string]SourceURLs{"git": giteaSource},
+	},
+	"forgejo": {
+		Type:   "git",
+		Source: map[string]SourceURLs{"git": giteaSource},
+	},
+	"sourcehut": {
+		Type: "git",
+		Source: map[string]SourceURLs{
+			"git": {
+				Home: "{url}",
+				Dir:  "{url}/tree/{branch}/item{/subdir}{/dir}",
+				File: "{url}/tree/{branch}/item{/subdir}{/dir}/{file}#L{line}",
+			},
+		},
+	},
+	"sourcehut-hg": {
+		Type: "hg",
+		Source: map[string]SourceURLs{
+			"hg": {
+				Home: "{url}",
+				Dir:  "{url}/browse{/subdir}{/dir}?rev={branch}",
+				File: "{url}/browse{/subdir}{/dir}/{file}?rev={branch}#L{line}",
+			},
+		},
+	},
+	"azure": {
+		Type: "git",
+		Source: map[string]SourceURLs{
+			"git": {
+				Home: "{url}",
+				Dir:  "{url}?path={/subdir}{/dir}&version=GB{branch}",
+				File: "{url}?path={/subdir}{/dir}/{file}&version=GB{branch}&line={line}&lineEnd={line}&lineStartColumn=1&lineEndColumn=1",
+			},
+		},
+	},
+}
+
+// A Host is the host name of a forge and the kind of forge it is, such as
+// git.example.com running Gitea.
+type Host struct {
+	Host string `json:"host"`
+	Kind string `json:"kind"`
+}
+
+// DefaultHosts are the public forges that are recognized without being
+// listed in a config.
+var DefaultHosts = []Host{
+	{Host: "github.com", Kind: "github"},
+	{Host: "gitlab.com", Kind: "gitlab"},
+	{Host: "bitbucket.org", Kind: "bitbucket"},
+	{Host: "codeberg.org", Kind: "forgejo"},
+	{Host: "gitea.com", Kind: "gitea"},
+	{Host: "git.sr.ht", Kind: "sourcehut"},
+	{Host: "hg.sr.ht", Kind: "sourcehut-hg"},
+	{Host: "dev.azure.com", Kind: "azure"},
+}
+
+// forge returns the forge that the repository URL is hosted on. Hosts listed
+// in the config take precedence over DefaultHosts.
+func (c Config) forge(repoURL string) (Forge, bool) {
+	u, err := url.Parse(repoURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return Forge{}, false
+	}
+	for _, hosts := range [][]Host{c.Hosts, DefaultHosts} {
+		for _, h := range hosts {
+			if strings.EqualFold(u.Host, h.Host) {
+				f, ok := Forges[h.Kind]
+				return f, ok
+			}
+		}
+	}
+	return Forge{}, false
+}
+
+// sourceURLs returns the go-source URLs for repository r, which has a type
+// and branch, hosted on forge f. It returns false if the forge does not host
+// repositories of the type.
+func (f Forge) sourceURLs(r Repository) (SourceURLs, bool) {
+	t, ok := f.Source[r.Type]
+	if !ok {
+		return SourceURLs{}, false
+	}
+	// Clone URLs often end in .git, but the pages for browsing the
+	// repository do not.
+	u := strings.TrimSuffix(strings.TrimSuffix(r.URL, "/"), ".git")
+	subdir := ""
+	if r.Subdir != "" {
+		subdir = "/" + r.Subdir
+	}
+	replacer := strings.NewReplacer("{url}", u, "{branch}", r.Branch, "{/subdir}", subdir)
+	return SourceURLs{
+		Home: replacer.Replace(t.Home),
+		Dir:  replacer.Replace(t.Dir),
+		File: replacer.Replace(t.File),
+	}, true
+}
+
+// forgeKinds returns the kinds in Forges, sorted.
+func forgeKinds() []string {
+	kinds := make([]string, 0, len(Forges))
+	for k := range Forges {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
@@ -0,0 +1,248 @@
+package vanity
+
+import "testing"
+
+func TestForgeSourceURLs(t *testing.T) {
+	testCases := []struct {
+		description  string
+		hosts        []Host
+		url          string
+		vcsType      string
+		branch       string
+		subdir       string
+		expectedOK   bool
+		expectedType string
+		expectedURLs SourceURLs
+	}{
+		{
+			description:  "github",
+			url:          "https://github.com/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://github.com/example/go-pkg1",
+				Dir:  "https://github.com/example/go-pkg1/tree/main{/dir}",
+				File: "https://github.com/example/go-pkg1/blob/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "github subdir",
+			url:          "https://github.com/example/monorepo",
+			branch:       "main",
+			subdir:       "go/pkg1",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://github.com/example/monorepo",
+				Dir:  "https://github.com/example/monorepo/tree/main/go/pkg1{/dir}",
+				File: "https://github.com/example/monorepo/blob/main/go/pkg1{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "gitlab",
+			url:          "https://gitlab.com/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://gitlab.com/example/go-pkg1",
+				Dir:  "https://gitlab.com/example/go-pkg1/-/tree/main{/dir}",
+				File: "https://gitlab.com/example/go-pkg1/-/blob/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "self-hosted gitlab subgroups",
+			hosts:        []Host{{Host: "git.corp.example", Kind: "gitlab"}},
+			url:          "https://git.corp.example/example/team/tools/go-pkg1.git",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://git.corp.example/example/team/tools/go-pkg1",
+				Dir:  "https://git.corp.example/example/team/tools/go-pkg1/-/tree/main{/dir}",
+				File: "https://git.corp.example/example/team/tools/go-pkg1/-/blob/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "github enterprise",
+			hosts:        []Host{{Host: "ghe.corp.example", Kind: "github"}},
+			url:          "https://GHE.corp.example/example/go-pkg1.git",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://GHE.corp.example/example/go-pkg1",
+				Dir:  "https://GHE.corp.example/example/go-pkg1/tree/main{/dir}",
+				File: "https://GHE.corp.example/example/go-pkg1/blob/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "host with port",
+			hosts:        []Host{{Host: "git.corp.example:8443", Kind: "gitea"}},
+			url:          "https://git.corp.example:8443/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://git.corp.example:8443/example/go-pkg1",
+				Dir:  "https://git.corp.example:8443/example/go-pkg1/src/branch/main{/dir}",
+				File: "https://git.corp.example:8443/example/go-pkg1/src/branch/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "bitbucket",
+			url:          "https://bitbucket.org/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://bitbucket.org/example/go-pkg1",
+				Dir:  "https://bitbucket.org/example/go-pkg1/src/main{/dir}",
+				File: "https://bitbucket.org/example/go-pkg1/src/main{/dir}/{file}#lines-{line}",
+			},
+		},
+		{
+			description:  "bitbucket hg",
+			url:          "https://bitbucket.org/example/go-pkg1",
+			vcsType:      "hg",
+			branch:       "default",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://bitbucket.org/example/go-pkg1",
+				Dir:  "https://bitbucket.org/example/go-pkg1/src/default{/dir}",
+				File: "https://bitbucket.org/example/go-pkg1/src/default{/dir}/{file}#lines-{line}",
+			},
+		},
+		{
+			description:  "type not hosted by forge",
+			url:          "https://github.com/example/go-pkg1",
+			vcsType:      "hg",
+			branch:       "default",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{},
+		},
+		{
+			description:  "codeberg",
+			url:          "https://codeberg.org/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://codeberg.org/example/go-pkg1",
+				Dir:  "https://codeberg.org/example/go-pkg1/src/branch/main{/dir}",
+				File: "https://codeberg.org/example/go-pkg1/src/branch/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "sourcehut git",
+			url:          "https://git.sr.ht/~example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://git.sr.ht/~example/go-pkg1",
+				Dir:  "https://git.sr.ht/~example/go-pkg1/tree/main/item{/dir}",
+				File: "https://git.sr.ht/~example/go-pkg1/tree/main/item{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "sourcehut hg",
+			url:          "https://hg.sr.ht/~example/go-pkg1",
+			branch:       "default",
+			expectedOK:   true,
+			expectedType: "hg",
+			expectedURLs: SourceURLs{
+				Home: "https://hg.sr.ht/~example/go-pkg1",
+				Dir:  "https://hg.sr.ht/~example/go-pkg1/browse{/dir}?rev=default",
+				File: "https://hg.sr.ht/~example/go-pkg1/browse{/dir}/{file}?rev=default#L{line}",
+			},
+		},
+		{
+			description:  "azure devops",
+			url:          "https://dev.azure.com/example/project/_git/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://dev.azure.com/example/project/_git/go-pkg1",
+				Dir:  "https://dev.azure.com/example/project/_git/go-pkg1?path={/dir}&version=GBmain",
+				File: "https://dev.azure.com/example/project/_git/go-pkg1?path={/dir}/{file}&version=GBmain&line={line}&lineEnd={line}&lineStartColumn=1&lineEndColumn=1",
+			},
+		},
+		{
+			description:  "azure devops subdir",
+			url:          "https://dev.azure.com/example/project/_git/monorepo",
+			branch:       "main",
+			subdir:       "go",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://dev.azure.com/example/project/_git/monorepo",
+				Dir:  "https://dev.azure.com/example/project/_git/monorepo?path=/go{/dir}&version=GBmain",
+				File: "https://dev.azure.com/example/project/_git/monorepo?path=/go{/dir}/{file}&version=GBmain&line={line}&lineEnd={line}&lineStartColumn=1&lineEndColumn=1",
+			},
+		},
+		{
+			description:  "configured host",
+			hosts:        []Host{{Host: "git.example.com", Kind: "gitea"}},
+			url:          "https://git.example.com/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://git.example.com/example/go-pkg1",
+				Dir:  "https://git.example.com/example/go-pkg1/src/branch/main{/dir}",
+				File: "https://git.example.com/example/go-pkg1/src/branch/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description:  "configured host overrides default",
+			hosts:        []Host{{Host: "github.com", Kind: "gitea"}},
+			url:          "https://github.com/example/go-pkg1",
+			branch:       "main",
+			expectedOK:   true,
+			expectedType: "git",
+			expectedURLs: SourceURLs{
+				Home: "https://github.com/example/go-pkg1",
+				Dir:  "https://github.com/example/go-pkg1/src/branch/main{/dir}",
+				File: "https://github.com/example/go-pkg1/src/branch/main{/dir}/{file}#L{line}",
+			},
+		},
+		{
+			description: "unknown host",
+			url:         "https://git.example.com/example/go-pkg1",
+			expectedOK:  false,
+		},
+		{
+			description: "not http",
+			url:         "ssh://github.com/example/go-pkg1",
+			expectedOK:  false,
+		},
+	}
+
+	for _, tc := range testCases {
+		c := Config{Hosts: tc.hosts}
+		f, ok := c.forge(tc.url)
+		if ok != tc.expectedOK {
+			t.Errorf("Test case %q got ok %v, want %v", tc.description, ok, tc.expectedOK)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if f.Type != tc.expectedType {
+			t.Errorf("Test case %q got type %q, want %q", tc.description, f.Type, tc.expectedType)
+		}
+		r := Repository{URL: tc.url, Type: tc.vcsType, Branch: tc.branch, Subdir: tc.subdir}
+		if r.Type == "" {
+			r.Type = f.Type
+		}
+		urls, _ := f.sourceURLs(r)
+		if urls != tc.expectedURLs {
+			t.Errorf("Test case %q got source urls %#v, want %#v", tc.description, urls, tc.expectedURLs)
+		}
+	}
+}
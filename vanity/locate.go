@@ -0,0 +1,358 @@
+package vanity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// position is a line and column in a config file, starting at 1.
+type position struct {
+	line, column int
+}
+
+// locate returns the position of path, or of its closest parent if path is
+// not in the file, such as for a required field that is missing.
+func locate(positions map[string]position, path string) position {
+	for path != "" {
+		if pos, ok := positions[path]; ok {
+			return pos
+		}
+		i := strings.LastIndexAny(path, ".[")
+		if i < 0 {
+			break
+		}
+		path = path[:i]
+	}
+	return position{}
+}
+
+// offsetPosition returns the position of the byte offset in data.
+func offsetPosition(data []byte, offset int) position {
+	if offset > len(data) {
+		offset = len(data)
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return position{line: line, column: utf8.RuneCount(before[lineStart:]) + 1}
+}
+
+// jsonPositions returns the position of every key and array element in the
+// JSON document, keyed by path. Data must be valid JSON.
+func jsonPositions(data []byte) map[string]position {
+	positions := map[string]position{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	// next returns the offset of the next token, skipping the separators
+	// that the decoder does not return as tokens.
+	next := func() int {
+		offset := int(dec.InputOffset())
+		for offset < len(data) && strings.IndexByte(" \t\r\n,:", data[offset]) >= 0 {
+			offset++
+		}
+		return offset
+	}
+
+	var walk func(path string) error
+	walk = func(path string) error {
+		if _, ok := positions[path]; !ok && path != "" {
+			positions[path] = offsetPosition(data, next())
+		}
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('{'):
+			for dec.More() {
+				keyOffset := next()
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				keyPath := joinPath(path, key.(string))
+				positions[keyPath] = offsetPosition(data, keyOffset)
+				err = walk(keyPath)
+				if err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		case json.Delim('['):
+			for i := 0; dec.More(); i++ {
+				err = walk(fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return err
+				}
+			}
+			_, err = dec.Token()
+			return err
+		}
+		return nil
+	}
+	walk("")
+
+	return positions
+}
+
+// yamlPositions returns the position of every key and sequence item in the
+// YAML document, keyed by path.
+func yamlPositions(doc *yaml.Node) map[string]position {
+	positions := map[string]position{}
+
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i], n.Content[i+1]
+				keyPath := joinPath(path, k.Value)
+				positions[keyPath] = position{line: k.Line, column: k.Column}
+				walk(v, keyPath)
+			}
+		case yaml.SequenceNode:
+			for i, c := range n.Content {
+				itemPath := fmt.Sprintf("%s[%d]", path, i)
+				positions[itemPath] = position{line: c.Line, column: c.Column}
+				walk(c, itemPath)
+			}
+		}
+	}
+	walk(doc, "")
+
+	return positions
+}
+
+// tomlPositions returns the position of every key, table and array element
+// in the TOML document, keyed by path. Data must be valid TOML.
+func tomlPositions(data []byte) map[string]position {
+	s := &tomlScanner{data: data, positions: map[string]position{}, tables: map[string]int{}}
+	table := ""
+	for s.skipSpace(true) {
+		start := s.offset
+		if s.data[s.offset] == '[' {
+			table = s.header()
+		} else {
+			s.keyValue(table)
+		}
+		if s.offset == start {
+			s.offset++
+		}
+	}
+	return s.positions
+}
+
+// tomlScanner finds the positions of keys in a TOML document. It only
+// understands as much TOML as needed to skip over values.
+type tomlScanner struct {
+	data      []byte
+	offset    int
+	positions map[string]position
+	// tables is the index of the last table in each array of tables, keyed
+	// by path.
+	tables map[string]int
+}
+
+// skipSpace skips whitespace and comments, and newlines if newlines is true.
+// It returns false at the end of the document.
+func (s *tomlScanner) skipSpace(newlines bool) bool {
+	for s.offset < len(s.data) {
+		switch c := s.data[s.offset]; {
+		case c == ' ' || c == '\t':
+			s.offset++
+		case c == '#':
+			for s.offset < len(s.data) && s.data[s.offset] != '\n' {
+				s.offset++
+			}
+		case newlines && (c == '\r' || c == '\n'):
+			s.offset++
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// peek returns the byte at the offset, or 0 at the end of the document.
+func (s *tomlScanner) peek() byte {
+	if s.offset < len(s.data) {
+		return s.data[s.offset]
+	}
+	return 0
+}
+
+// mark records the position of the offset as the position of path, unless
+// path already has one.
+func (s *tomlScanner) mark(path string, offset int) {
+	if _, ok := s.positions[path]; !ok && path != "" {
+		s.positions[path] = offsetPosition(s.data, offset)
+	}
+}
+
+// header reads a table or array of tables header and returns the path of
+// the table.
+func (s *tomlScanner) header() string {
+	start := s.offset
+	array := bytes.HasPrefix(s.data[s.offset:], []byte("[["))
+	s.offset++
+	if array {
+		s.offset++
+	}
+	keys := s.key()
+	path := ""
+	for i, k := range keys {
+		path = joinPath(path, k)
+		last := i == len(keys)-1
+		if last && array {
+			s.mark(path, start)
+			index := 0
+			if prev, ok := s.tables[path]; ok {
+				index = prev + 1
+			}
+			s.tables[path] = index
+			path = fmt.Sprintf("%s[%d]", path, index)
+		} else if index, ok := s.tables[path]; ok && !last {
+			path = fmt.Sprintf("%s[%d]", path, index)
+		}
+		s.mark(path, start)
+	}
+	s.skipSpace(false)
+	for s.peek() == ']' {
+		s.offset++
+	}
+	return path
+}
+
+// keyValue reads a key and its value in the table at path.
+func (s *tomlScanner) keyValue(table string) {
+	start := s.offset
+	keys := s.key()
+	if len(keys) == 0 {
+		return
+	}
+	path := table
+	for _, k := range keys {
+		path = joinPath(path, k)
+		s.mark(path, start)
+	}
+	s.skipSpace(false)
+	if s.peek() == '=' {
+		s.offset++
+	}
+	s.value(path)
+}
+
+// key reads a dotted key, returning its parts.
+func (s *tomlScanner) key() []string {
+	var keys []string
+	for {
+		s.skipSpace(false)
+		start := s.offset
+		switch s.peek() {
+		case '"':
+			s.skipString()
+			k, err := strconv.Unquote(string(s.data[start:s.offset]))
+			if err != nil {
+				k = string(s.data[start+1 : s.offset-1])
+			}
+			keys = append(keys, k)
+		case '\'':
+			s.skipString()
+			keys = append(keys, string(s.data[start+1:s.offset-1]))
+		default:
+			for s.offset < len(s.data) && isTOMLBareKeyByte(s.data[s.offset]) {
+				s.offset++
+			}
+			if s.offset == start {
+				return keys
+			}
+			keys = append(keys, string(s.data[start:s.offset]))
+		}
+		s.skipSpace(false)
+		if s.peek() != '.' {
+			return keys
+		}
+		s.offset++
+	}
+}
+
+func isTOMLBareKeyByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// value reads the value at path, recording the positions of the keys and
+// elements of inline tables and arrays.
+func (s *tomlScanner) value(path string) {
+	s.skipSpace(false)
+	switch s.peek() {
+	case '"', '\'':
+		s.skipString()
+	case '[':
+		s.offset++
+		for i := 0; s.skipSpace(true) && s.peek() != ']'; i++ {
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			s.mark(elemPath, s.offset)
+			s.value(elemPath)
+			s.skipSpace(true)
+			if s.peek() == ',' {
+				s.offset++
+			}
+		}
+		s.offset++
+	case '{':
+		s.offset++
+		for s.skipSpace(true) && s.peek() != '}' {
+			start := s.offset
+			s.keyValue(path)
+			s.skipSpace(true)
+			if s.peek() == ',' {
+				s.offset++
+			}
+			if s.offset == start {
+				s.offset++
+			}
+		}
+		s.offset++
+	default:
+		for s.offset < len(s.data) && strings.IndexByte(",]}#\r\n", s.data[s.offset]) < 0 {
+			s.offset++
+		}
+	}
+}
+
+// skipString skips a basic, literal or multi-line string.
+func (s *tomlScanner) skipString() {
+	quote := s.data[s.offset]
+	delim := []byte{quote}
+	if bytes.HasPrefix(s.data[s.offset:], []byte{quote, quote, quote}) {
+		delim = []byte{quote, quote, quote}
+	}
+	s.offset += len(delim)
+	for s.offset < len(s.data) {
+		if quote == '"' && s.data[s.offset] == '\\' {
+			s.offset += 2
+			continue
+		}
+		if bytes.HasPrefix(s.data[s.offset:], delim) {
+			s.offset += len(delim)
+			// Multi-line strings can end with up to two quotes before
+			// the delimiter.
+			for n := 0; len(delim) == 3 && n < 2 && s.peek() == quote; n++ {
+				s.offset++
+			}
+			return
+		}
+		s.offset++
+	}
+}
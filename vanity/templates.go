@@ -0,0 +1,97 @@
+package vanity
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+)
+
+// Templates are the html/template templates that a Generator renders pages
+// with. Package pages are rendered with PackageData and the index page with
+// IndexData. Pages whose template is nil are rendered with the built-in
+// template of the config's theme.
+type Templates struct {
+	Package *template.Template
+	Index   *template.Template
+}
+
+// ParseTemplates parses the templates in the root of fsys. The templates
+// package.html and index.html replace the built-in pages. Every other .html
+// file is parsed with each page, so the blocks it defines replace the blocks
+// of the built-in pages, or of package.html and index.html, of the same name.
+// The built-in pages are those of theme.
+//
+// Templates can call the asset function with the name of one of assets to
+// get the URL it is served at, such as {{asset "logo.png"}}. The URL is from
+// the root of the site, so pages served below a BasePath link to assets with
+// {{.BasePath}}{{asset "logo.png"}}. Assets may be nil if there are none.
+func ParseTemplates(fsys fs.FS, theme string, assets *Assets) (Templates, error) {
+	names, err := fs.Glob(fsys, "*.html")
+	if err != nil {
+		return Templates{}, err
+	}
+	if len(names) == 0 {
+		return Templates{}, errors.New("no .html templates found")
+	}
+
+	funcs := template.FuncMap{"asset": assets.URL}
+	t, err := themeTemplates(theme)
+	if err != nil {
+		return Templates{}, err
+	}
+	t.Package.Funcs(funcs)
+	t.Index.Funcs(funcs)
+	pages := []struct {
+		name string
+		tmpl **template.Template
+	}{
+		{"package.html", &t.Package},
+		{"index.html", &t.Index},
+	}
+	for _, p := range pages {
+		data, err := fs.ReadFile(fsys, p.name)
+		if err == nil {
+			*p.tmpl, err = template.New(p.name).Funcs(funcs).Parse(string(data))
+			if err != nil {
+				return Templates{}, fmt.Errorf("parsing template %s: %w", p.name, err)
+			}
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return Templates{}, err
+		}
+	}
+
+	for _, name := range names {
+		if name == "package.html" || name == "index.html" {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return Templates{}, err
+		}
+		for _, p := range pages {
+			_, err = (*p.tmpl).New(name).Parse(string(data))
+			if err != nil {
+				return Templates{}, fmt.Errorf("parsing template %s: %w", name, err)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// packageTemplate returns the template for package pages.
+func (t Templates) packageTemplate(theme string) *template.Template {
+	if t.Package != nil {
+		return t.Package
+	}
+	return builtinTheme(theme).Package
+}
+
+// indexTemplate returns the template for the index page.
+func (t Templates) indexTemplate(theme string) *template.Template {
+	if t.Index != nil {
+		return t.Index
+	}
+	return builtinTheme(theme).Index
+}
@@ -0,0 +1,216 @@
+package vanity
+
+import (
+	"bytes"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParseTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"package.html": {Data: []byte(`<html><head><title>{{.Package}}</title>{{/* no meta tags */}}</head><body>{{.HomeURL}}</body></html>`)},
+		"index.html":   {Data: []byte(`{{.Domain}}:{{range .PackageRepositories}} {{.Prefix}}{{end}}`)},
+	}
+	templates, err := ParseTemplates(fsys, "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/go-pkg1"},
+			{Prefix: "pkg2", Type: "git", URL: "https://example.com/git/pkg2"},
+		},
+	})
+	g.Templates = templates
+
+	var index bytes.Buffer
+	err = g.WriteIndex(&index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g, w := index.String(), "example.com: pkg1 pkg2"; g != w {
+		t.Errorf("Got index %q, want %q", g, w)
+	}
+
+	var pkg bytes.Buffer
+	err = g.WritePackage(&pkg, "pkg1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedPackage := `<html><head>
+<meta name="go-import" content="example.com/pkg1 git https://github.com/example/go-pkg1">
+<meta name="go-source" content="example.com/pkg1 https://github.com/example/go-pkg1 https://github.com/example/go-pkg1/tree/master{/dir} https://github.com/example/go-pkg1/blob/master{/dir}/{file}#L{line}"><title>pkg1</title></head><body>https://pkg.go.dev/example.com/pkg1</body></html>`
+	if g, w := pkg.String(), expectedPackage; g != w {
+		t.Errorf("Got package:\n%s\nwant:\n%s", g, w)
+	}
+}
+
+func TestParseTemplatesBlocks(t *testing.T) {
+	fsys := fstest.MapFS{
+		"blocks.html": {Data: []byte(`{{define "footer"}}<footer>Acme {{.Domain}}</footer>{{end}}
+{{define "index-section"}}<h3>{{.Title}}</h3>{{range .Repositories}} {{.Prefix}}{{end}}
+{{end}}`)},
+	}
+	templates, err := ParseTemplates(fsys, "dark", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGenerator(Config{
+		Domain: "example.com",
+		Index:  true,
+		Theme:  "dark",
+		Repositories: []Repository{
+			{Prefix: "pkg1", Main: true, URL: "https://github.com/example/go-pkg1"},
+			{Prefix: "pkg2", URL: "https://github.com/example/go-pkg2"},
+		},
+	})
+	g.Templates = templates
+
+	var index bytes.Buffer
+	err = g.WriteIndex(&index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<meta name="color-scheme" content="dark">`,
+		"<h3>Tools</h3> pkg1\n",
+		"<h3>Libraries</h3> pkg2\n",
+		"<footer>Acme example.com</footer>\n</div>",
+	} {
+		if !strings.Contains(index.String(), want) {
+			t.Errorf("Got index:\n%s\nwant it to contain %q", index.String(), want)
+		}
+	}
+
+	var pkg bytes.Buffer
+	err = g.WritePackage(&pkg, "pkg1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<meta name="color-scheme" content="dark">`,
+		`<meta name="go-import" content="example.com/pkg1 git https://github.com/example/go-pkg1">`,
+		"<footer>Acme example.com</footer></div>",
+	} {
+		if !strings.Contains(pkg.String(), want) {
+			t.Errorf("Got package:\n%s\nwant it to contain %q", pkg.String(), want)
+		}
+	}
+}
+
+func TestThemes(t *testing.T) {
+	c := Config{
+		Domain: "example.com",
+		Index:  true,
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/go-pkg1"},
+		},
+	}
+	// Unknown themes, which Validate reports, are rendered with the plain
+	// theme.
+	for _, theme := range append(themeNames(), "Dark") {
+		c.Theme = theme
+		files, err := NewGenerator(c).FS()
+		if err != nil {
+			t.Errorf("Theme %q got error %v", theme, err)
+			continue
+		}
+		pkg, err := fs.ReadFile(files, "pkg1/index.html")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `<meta name="go-import" content="example.com/pkg1 git https://github.com/example/go-pkg1">`
+		if !strings.Contains(string(pkg), want) {
+			t.Errorf("Theme %q got package:\n%s\nwant it to contain %q", theme, pkg, want)
+		}
+	}
+}
+
+func TestParseTemplatesErrors(t *testing.T) {
+	testCases := []struct {
+		description string
+		fsys        fstest.MapFS
+		expectedErr string
+	}{
+		{
+			description: "no templates",
+			fsys:        fstest.MapFS{"logo.png": {}},
+			expectedErr: "no .html templates found",
+		},
+		{
+			description: "invalid template",
+			fsys:        fstest.MapFS{"index.html": {Data: []byte("{{.Domain")}},
+			expectedErr: `parsing template index.html: template: index.html:1: unclosed action`,
+		},
+		{
+			description: "invalid block",
+			fsys:        fstest.MapFS{"blocks.html": {Data: []byte(`{{define "footer"}}`)}},
+			expectedErr: `parsing template blocks.html: template: blocks.html:1: unexpected EOF`,
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseTemplates(tc.fsys, "", nil)
+		if err == nil || err.Error() != tc.expectedErr {
+			t.Errorf("Test case %q got err %v, want %q", tc.description, err, tc.expectedErr)
+		}
+	}
+}
+
+func TestEnsureMetaTags(t *testing.T) {
+	data := PackageData{
+		GoImport: "example.com/pkg1 git https://example.com/pkg1",
+		GoSource: "example.com/pkg1 _ _ _",
+	}
+	const goImport = `<meta name="go-import" content="example.com/pkg1 git https://example.com/pkg1">`
+	const goSource = `<meta name="go-source" content="example.com/pkg1 _ _ _">`
+
+	testCases := []struct {
+		description string
+		page        string
+		expected    string
+	}{
+		{
+			description: "both present",
+			page:        "<head>\n" + goImport + "\n" + goSource + "\n</head>",
+			expected:    "<head>\n" + goImport + "\n" + goSource + "\n</head>",
+		},
+		{
+			description: "present with other quoting and case",
+			page:        "<HEAD><META content='x' NAME='go-import'><meta name=go-source content=y></HEAD>",
+			expected:    "<HEAD><META content='x' NAME='go-import'><meta name=go-source content=y></HEAD>",
+		},
+		{
+			description: "go-source missing",
+			page:        "<!DOCTYPE html>\n<html>\n<head lang=\"en\">\n" + goImport + "\n</head>",
+			expected:    "<!DOCTYPE html>\n<html>\n<head lang=\"en\">\n" + goSource + "\n" + goImport + "\n</head>",
+		},
+		{
+			description: "both missing without head",
+			page:        "<!DOCTYPE html>\n<html>\n<body></body>\n</html>",
+			expected:    "<!DOCTYPE html>\n<html>\n" + goImport + "\n" + goSource + "\n<body></body>\n</html>",
+		},
+		{
+			description: "both missing without html",
+			page:        "<p>hello</p>",
+			expected:    goImport + "\n" + goSource + "\n<p>hello</p>",
+		},
+		{
+			description: "name in text is not a tag",
+			page:        "<head></head><body>go-import</body>",
+			expected:    "<head>\n" + goImport + "\n" + goSource + "</head><body>go-import</body>",
+		},
+	}
+
+	for _, tc := range testCases {
+		out := string(ensureMetaTags([]byte(tc.page), data))
+		if out != tc.expected {
+			t.Errorf("Test case %q got:\n%s\nwant:\n%s", tc.description, out, tc.expected)
+		}
+	}
+}
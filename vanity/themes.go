@@ -0,0 +1,97 @@
+package vanity
+
+import (
+	"html/template"
+	"sort"
+)
+
+// themes are the built-in themes, keyed by name. Each is a set of template
+// definitions that replace blocks of the built-in pages, and is rendered with
+// either PackageData or IndexData, so may only use the fields they share.
+var themes = map[string]string{
+	"plain": ``,
+	"dark": `{{define "style"}}<meta name="color-scheme" content="dark">
+<style>
+* { font-family: sans-serif; }
+body { margin-top: 0; background-color: #1b1b1f; color: #d8d8dc; }
+a { color: #7cb7ff; }
+a:visited { color: #b69cff; }
+.content { display: inline-block; }
+code { display: block; font-family: monospace; font-size: 1em; background-color: #2b2b31; padding: 1em; margin-bottom: 16px; }
+ul { margin-top: 16px; margin-bottom: 16px; }
+hr { border: 0; border-top: 1px solid #3b3b42; }
+</style>
+{{end}}`,
+	"docs": `{{define "style"}}<meta name="viewport" content="width=device-width, initial-scale=1">
+<style>
+body { margin: 0; font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; line-height: 1.5; color: #202224; }
+.content { display: block; max-width: 50rem; margin: 0 auto; padding: 0 1.5rem 1.5rem; }
+h2 { font-size: 1.75rem; font-weight: normal; }
+h3 { margin-top: 2rem; border-bottom: 1px solid #dadce0; padding-bottom: 0.25rem; }
+a { color: #007d9c; }
+code { display: block; font-family: Menlo, Consolas, monospace; font-size: 0.875rem; background-color: #f8f8f8; border: 1px solid #dadce0; border-radius: 4px; padding: 0.75rem 1rem; margin-bottom: 1rem; }
+ul { margin-top: 1rem; margin-bottom: 1rem; }
+hr { border: 0; border-top: 1px solid #dadce0; margin-top: 2rem; }
+</style>
+{{end}}{{define "footer"}}<hr/>
+
+Generated by <a href="https://4d63.com/vangen">vangen</a>.
+{{end}}`,
+}
+
+// builtinTemplates are the built-in templates of each theme, keyed by theme
+// name. The plain theme is also keyed by the empty name.
+var builtinTemplates = func() map[string]Templates {
+	m := map[string]Templates{}
+	for name := range themes {
+		t, err := themeTemplates(name)
+		if err != nil {
+			panic(err)
+		}
+		m[name] = t
+	}
+	m[""] = m["plain"]
+	return m
+}()
+
+// builtinTheme returns the built-in templates of theme, or of the plain theme
+// if theme is not a built-in theme.
+func builtinTheme(theme string) Templates {
+	t, ok := builtinTemplates[theme]
+	if !ok {
+		return builtinTemplates[""]
+	}
+	return t
+}
+
+// themeTemplates parses the built-in templates with the blocks of theme,
+// returning templates that have not been executed so blocks can be replaced.
+func themeTemplates(theme string) (Templates, error) {
+	var t Templates
+	var err error
+	t.Package, err = template.New("package.html").Parse(packageHTML)
+	if err != nil {
+		return Templates{}, err
+	}
+	t.Index, err = template.New("index.html").Parse(indexHTML)
+	if err != nil {
+		return Templates{}, err
+	}
+	for _, tmpl := range []*template.Template{t.Package, t.Index} {
+		_, err = tmpl.New("theme").Parse(themes[theme])
+		if err != nil {
+			return Templates{}, err
+		}
+	}
+	return t, nil
+}
+
+// themeNames returns the names of the built-in themes, sorted.
+func themeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
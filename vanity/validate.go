@@ -0,0 +1,379 @@
+package vanity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/module"
+)
+
+// vcsTypes are the repository types supported by the go-import meta tag.
+var vcsTypes = []string{"git", "hg", "svn", "bzr", "fossil", "mod"}
+
+// defaultBranches are the branches source URLs link to for repositories of
+// each type that have no branch configured. Module proxies have no branches.
+var defaultBranches = map[string]string{
+	"git":    "master",
+	"hg":     "default",
+	"svn":    "trunk",
+	"bzr":    "trunk",
+	"fossil": "trunk",
+}
+
+// A Problem is a single problem found when validating a config.
+type Problem struct {
+	// Path is the location of the problem in the config, such as
+	// repositories[2].url.
+	Path string
+	// Repository is the index of the repository the problem is in, in the
+	// order the repositories are listed in the config file, or -1 if the
+	// problem is not in a repository.
+	Repository int
+	// Prefix is the prefix of the repository the problem is in.
+	Prefix string
+	// Line and Column are the position of the problem in the config file,
+	// starting at 1. They are zero if the position is not known.
+	Line, Column int
+	Message      string
+}
+
+func (p Problem) String() string {
+	var sb strings.Builder
+	if p.Line > 0 {
+		fmt.Fprintf(&sb, "%d:%d: ", p.Line, p.Column)
+	}
+	sb.WriteString(p.Path)
+	if p.Repository >= 0 {
+		fmt.Fprintf(&sb, " (prefix %q)", p.Prefix)
+	}
+	sb.WriteString(": ")
+	sb.WriteString(p.Message)
+	return sb.String()
+}
+
+// ValidationError is returned when a config has one or more problems.
+type ValidationError struct {
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Validate reports every problem found in the config that would prevent
+// pages from being generated or served correctly. The error returned is a
+// *ValidationError. Problems found by Validate have no line or column, use
+// ParseConfig to validate a config file with positions.
+func (c Config) Validate() error {
+	problems := c.validate()
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (c Config) validate() []Problem {
+	var problems []Problem
+	add := func(repo int, path, format string, args ...interface{}) {
+		p := Problem{Path: path, Repository: repo, Message: fmt.Sprintf(format, args...)}
+		if repo >= 0 {
+			p.Prefix = c.Repositories[repo].Prefix
+		}
+		problems = append(problems, p)
+	}
+
+	if c.Domain == "" {
+		add(-1, "domain", "is required")
+	} else if strings.Contains(c.Domain, "://") {
+		add(-1, "domain", "must be a domain without a scheme, such as example.com")
+	}
+	if strings.Contains(c.DocsDomain, "://") {
+		add(-1, "docsDomain", "must be a domain without a scheme, such as pkg.go.dev")
+	}
+	if strings.ContainsAny(c.Branch, " \t\n") {
+		add(-1, "branch", "must not contain spaces, got %q", c.Branch)
+	}
+
+	if c.Proxy != "" && !isAbsoluteURL(c.Proxy) {
+		add(-1, "proxy", "must be an absolute URL, got %q", c.Proxy)
+	}
+	if _, ok := themes[c.Theme]; c.Theme != "" && !ok {
+		add(-1, "theme", "unknown theme %q, must be one of %s", c.Theme, strings.Join(themeNames(), ", "))
+	}
+
+	for i, h := range c.Hosts {
+		path := fmt.Sprintf("hosts[%d]", i)
+		if h.Host == "" {
+			add(-1, path+".host", "is required")
+		} else if strings.Contains(h.Host, "/") {
+			add(-1, path+".host", "must be a host name without a scheme or path, such as git.example.com")
+		}
+		if _, ok := Forges[h.Kind]; !ok {
+			add(-1, path+".kind", "unknown kind %q, must be one of %s", h.Kind, strings.Join(forgeKinds(), ", "))
+		}
+	}
+
+	prefixes := map[string]bool{}
+	for _, r := range c.Repositories {
+		prefixes[r.Prefix] = true
+	}
+	for i, s := range c.Sections {
+		path := fmt.Sprintf("sections[%d]", i)
+		if s.Title == "" {
+			add(-1, path+".title", "is required")
+		}
+		for j, p := range s.Prefixes {
+			if !prefixes[p] {
+				add(-1, fmt.Sprintf("%s.prefixes[%d]", path, j), "no repository has the prefix %q", p)
+			}
+		}
+		for j, t := range s.Tags {
+			if t == "" {
+				add(-1, fmt.Sprintf("%s.tags[%d]", path, j), "must not be empty")
+			}
+		}
+	}
+
+	packages := map[string]string{}
+	for i, r := range c.Repositories {
+		path := fmt.Sprintf("repositories[%d]", i)
+
+		if r.URL == "" {
+			add(i, path+".url", "is required")
+		} else if !isAbsoluteURL(r.URL) {
+			add(i, path+".url", "must be an absolute URL, got %q", r.URL)
+		}
+
+		if r.Proxy != "" && r.Proxy != "direct" && !isAbsoluteURL(r.Proxy) {
+			add(i, path+".proxy", "must be an absolute URL or direct, got %q", r.Proxy)
+		}
+
+		if r.Type == "" {
+			if _, ok := c.forge(r.URL); isAbsoluteURL(r.URL) && !ok && c.proxy(r) == "" {
+				add(i, path+".type", "is required when it cannot be inferred from the url, must be one of %s", strings.Join(vcsTypes, ", "))
+			}
+		} else if !contains(vcsTypes, r.Type) {
+			add(i, path+".type", "unsupported type %q, must be one of %s", r.Type, strings.Join(vcsTypes, ", "))
+		}
+
+		if strings.ContainsAny(r.Branch, " \t\n") {
+			add(i, path+".branch", "must not contain spaces, got %q", r.Branch)
+		}
+
+		if r.Branch == BranchClone && r.Clone == "" {
+			add(i, path+".branch", "must not be %q unless clone is set", BranchClone)
+		}
+
+		if r.Discover && r.Clone == "" {
+			add(i, path+".discover", "requires clone to be set")
+		}
+
+		if r.Subdir != "" && (!fs.ValidPath(r.Subdir) || r.Subdir == ".") {
+			add(i, path+".subdir", "must be a relative path to a directory in the repository, got %q", r.Subdir)
+		}
+
+		for j, t := range r.Tags {
+			if t == "" {
+				add(i, fmt.Sprintf("%s.tags[%d]", path, j), "must not be empty")
+			}
+		}
+
+		if r.Website.URL != "" && !isAbsoluteURL(r.Website.URL) {
+			add(i, path+".website.url", "must be an absolute URL, got %q", r.Website.URL)
+		}
+
+		if r.Prefix != "" {
+			err := checkPackagePath(r.Prefix)
+			if err != nil {
+				add(i, path+".prefix", "%v", err)
+				continue
+			}
+		}
+		if other, ok := packages[r.Prefix]; ok {
+			add(i, path+".prefix", "package %q is also generated by %s", r.Prefix, other)
+		} else {
+			packages[r.Prefix] = path + ".prefix"
+		}
+		for j, s := range r.Subs {
+			subPath := fmt.Sprintf("%s.subs[%d]", path, j)
+			for k, t := range s.Tags {
+				if t == "" {
+					add(i, fmt.Sprintf("%s.tags[%d]", subPath, k), "must not be empty")
+				}
+			}
+			if s.Name == "" {
+				add(i, subPath, "name is required")
+				continue
+			}
+			err := checkPackagePath(s.Name)
+			if err != nil {
+				add(i, subPath, "%v", err)
+				continue
+			}
+			pkg := r.SubPath(j)
+			if other, ok := packages[pkg]; ok {
+				add(i, subPath, "package %q is also generated by %s", pkg, other)
+			} else {
+				packages[pkg] = subPath
+			}
+		}
+		for j, v := range r.Versions {
+			versionPath := fmt.Sprintf("%s.versions[%d]", path, j)
+			if _, major, ok := module.SplitPathVersion("x/" + v.Version); !ok || major != "/"+v.Version {
+				add(i, versionPath, "must be a major version of 2 or later, such as v2, got %q", v.Version)
+				continue
+			}
+			if v.URL != "" && !isAbsoluteURL(v.URL) {
+				add(i, versionPath+".url", "must be an absolute URL, got %q", v.URL)
+			}
+			if strings.ContainsAny(v.Branch, " \t\n") {
+				add(i, versionPath+".branch", "must not contain spaces, got %q", v.Branch)
+			}
+			for _, pkg := range r.versionRepositories()[j].Packages() {
+				if other, ok := packages[pkg]; ok {
+					add(i, versionPath, "package %q is also generated by %s", pkg, other)
+				} else {
+					packages[pkg] = versionPath
+				}
+			}
+		}
+	}
+
+	// Packages are served by the repository with the longest prefix of
+	// their path, so a sub or version below the prefix of another
+	// repository would get that repository's meta tags.
+	for i, r := range c.Repositories {
+		path := fmt.Sprintf("repositories[%d]", i)
+		overlaps := func(p, pkg string) {
+			for j, other := range c.Repositories {
+				if j != i && len(other.Prefix) > len(r.Prefix) && strings.HasPrefix(pkg, other.Prefix+"/") {
+					add(i, p, "package %q is below the prefix of repositories[%d] and would be served by it", pkg, j)
+					return
+				}
+			}
+		}
+		for j, s := range r.Subs {
+			if s.Name != "" && checkPackagePath(s.Name) == nil {
+				overlaps(fmt.Sprintf("%s.subs[%d]", path, j), r.SubPath(j))
+			}
+		}
+		for j, vr := range r.versionRepositories() {
+			for _, pkg := range vr.Packages() {
+				overlaps(fmt.Sprintf("%s.versions[%d]", path, j), pkg)
+			}
+		}
+	}
+
+	return problems
+}
+
+// checkPackagePath returns an error if p is not a relative import path that
+// the go command accepts, such as a path with a leading slash, with "." or
+// ".." elements, or with backslashes or other disallowed characters. Package
+// paths that pass are safe to use as paths in the output directory.
+func checkPackagePath(p string) error {
+	if strings.HasPrefix(p, "/") {
+		return fmt.Errorf("invalid package path %q: leading slash", p)
+	}
+	err := module.CheckImportPath(p)
+	var pathErr *module.InvalidPathError
+	if errors.As(err, &pathErr) {
+		return fmt.Errorf("invalid package path %q: %v", p, pathErr.Err)
+	}
+	return err
+}
+
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.Scheme != "" && u.Host != ""
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFields compares a decoded but untyped config value with the type it
+// will be decoded into, reporting fields that are unknown and values that
+// have the wrong type.
+func checkFields(v interface{}, t reflect.Type, path string, add func(path, format string, args ...interface{})) {
+	if v == nil {
+		return
+	}
+
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		// Types with their own unmarshaler, such as Sub, accept a string
+		// shorthand in place of an object.
+		if _, ok := v.(string); ok {
+			return
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			add(path, "must be an object")
+			return
+		}
+		fields := map[string]reflect.StructField{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if f.IsExported() && name != "" && name != "-" {
+				fields[name] = f
+			}
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			f, ok := fields[k]
+			if !ok {
+				add(joinPath(path, k), "unknown field %q", k)
+				continue
+			}
+			checkFields(m[k], f.Type, joinPath(path, k), add)
+		}
+	case reflect.Slice:
+		l, ok := v.([]interface{})
+		if !ok {
+			add(path, "must be a list")
+			return
+		}
+		for i, e := range l {
+			checkFields(e, t.Elem(), fmt.Sprintf("%s[%d]", path, i), add)
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			add(path, "must be a string")
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			add(path, "must be true or false")
+		}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,437 @@
+package vanity
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigValidation(t *testing.T) {
+	testCases := []struct {
+		description string
+		format      string
+		config      string
+		expectedErr string
+	}{
+		{
+			description: "valid",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "subs": ["sub1"]},
+    {"prefix": "pkg1/sub2", "url": "https://example.com/go-pkg1-sub2", "type": "hg"}
+  ]
+}`,
+		},
+		{
+			description: "missing domain",
+			format:      FormatJSON,
+			config:      `{}`,
+			expectedErr: `domain: is required`,
+		},
+		{
+			description: "unknown fields",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "docDomain": "pkg.go.dev",
+  "repositories": [
+    {
+      "prefix": "pkg1",
+      "url": "https://github.com/example/go-pkg1",
+      "hiden": true,
+      "subs": [
+        "sub1",
+        {"name": "sub2", "hidden": true, "descr": "x"}
+      ]
+    }
+  ]
+}`,
+			expectedErr: `3:3: docDomain: unknown field "docDomain"
+8:7: repositories[0].hiden (prefix "pkg1"): unknown field "hiden"
+11:42: repositories[0].subs[1].descr (prefix "pkg1"): unknown field "descr"`,
+		},
+		{
+			description: "unknown fields and other problems",
+			format:      FormatYAML,
+			config: `domain: example.com
+repositories:
+- prefix: pkg1
+  url: https://github.com/example/go-pkg1
+  hiden: true
+- prefix: pkg1
+  url: notaurl
+  type: cvs
+`,
+			expectedErr: `5:3: repositories[0].hiden (prefix "pkg1"): unknown field "hiden"
+6:3: repositories[1].prefix (prefix "pkg1"): package "pkg1" is also generated by repositories[0].prefix
+7:3: repositories[1].url (prefix "pkg1"): must be an absolute URL, got "notaurl"
+8:3: repositories[1].type (prefix "pkg1"): unsupported type "cvs", must be one of git, hg, svn, bzr, fossil, mod`,
+		},
+		{
+			description: "wrong types",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "index": "yes",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "subs": [1]}
+  ]
+}`,
+			expectedErr: `3:3: index: must be true or false
+5:78: repositories[0].subs[0] (prefix "pkg1"): must be an object`,
+		},
+		{
+			description: "repository problems",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1"},
+    {"prefix": "pkg2", "url": "github.com/example/go-pkg2"},
+    {"prefix": "pkg3", "url": "https://example.com/go-pkg3"},
+    {"prefix": "pkg4", "url": "https://github.com/example/go-pkg4", "type": "cvs"},
+    {"prefix": "pkg5", "url": "https://github.com/example/go-pkg5", "website": {"url": "/home"}}
+  ]
+}`,
+			expectedErr: `4:5: repositories[0].url (prefix "pkg1"): is required
+5:24: repositories[1].url (prefix "pkg2"): must be an absolute URL, got "github.com/example/go-pkg2"
+6:5: repositories[2].type (prefix "pkg3"): is required when it cannot be inferred from the url, must be one of git, hg, svn, bzr, fossil, mod
+7:69: repositories[3].type (prefix "pkg4"): unsupported type "cvs", must be one of git, hg, svn, bzr, fossil, mod
+8:81: repositories[4].website.url (prefix "pkg5"): must be an absolute URL, got "/home"`,
+		},
+		{
+			description: "overlapping packages",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "a", "url": "https://github.com/example/a", "subs": ["b/c", "e/f"], "versions": ["v2"]},
+    {"prefix": "a/b", "url": "https://github.com/example/a-b"},
+    {"prefix": "a/v2/e", "url": "https://github.com/example/a-v2-e"}
+  ]
+}`,
+			expectedErr: `4:69: repositories[0].subs[0] (prefix "a"): package "a/b/c" is below the prefix of repositories[1] and would be served by it
+4:97: repositories[0].versions[0] (prefix "a"): package "a/v2/e/f" is below the prefix of repositories[2] and would be served by it`,
+		},
+		{
+			description: "hosts",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "hosts": [
+    {"host": "git.example.com", "kind": "gitea"},
+    {"host": "https://code.example.com", "kind": "gogs"},
+    {"kind": "github"}
+  ],
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://git.example.com/example/go-pkg1"}
+  ]
+}`,
+			expectedErr: `5:6: hosts[1].host: must be a host name without a scheme or path, such as git.example.com
+5:42: hosts[1].kind: unknown kind "gogs", must be one of azure, bitbucket, forgejo, gitea, github, gitlab, sourcehut, sourcehut-hg
+6:5: hosts[2].host: is required`,
+		},
+		{
+			description: "proxies",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "proxy": "athens.example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://example.com/go-pkg1", "proxy": "https://athens.example.com"},
+    {"prefix": "pkg2", "url": "https://example.com/go-pkg2", "proxy": "off"}
+  ]
+}`,
+			expectedErr: `3:3: proxy: must be an absolute URL, got "athens.example.com"
+6:62: repositories[1].proxy (prefix "pkg2"): must be an absolute URL or direct, got "off"`,
+		},
+		{
+			description: "clone branch",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "branch": "clone",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "clone": "../go-pkg1", "branch": "clone"},
+    {"prefix": "pkg2", "url": "https://github.com/example/go-pkg2", "branch": "clone"}
+  ]
+}`,
+			expectedErr: `6:69: repositories[1].branch (prefix "pkg2"): must not be "clone" unless clone is set`,
+		},
+		{
+			description: "theme",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "theme": "solarized",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://example.com/go-pkg1", "type": "git"}
+  ]
+}`,
+			expectedErr: `3:3: theme: unknown theme "solarized", must be one of dark, docs, plain`,
+		},
+		{
+			description: "sections",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "sections": [
+    {"title": "Featured", "prefixes": ["pkg1", "pkg2"]},
+    {"tags": ["db", ""]}
+  ],
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "tags": ["db", ""],
+     "subs": [{"name": "sub1", "tags": [""]}]}
+  ]
+}`,
+			expectedErr: `4:48: sections[0].prefixes[1]: no repository has the prefix "pkg2"
+5:5: sections[1].title: is required
+5:21: sections[1].tags[1]: must not be empty
+8:84: repositories[0].tags[1] (prefix "pkg1"): must not be empty
+9:41: repositories[0].subs[0].tags[0] (prefix "pkg1"): must not be empty`,
+		},
+		{
+			description: "subdirs",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/monorepo", "subdir": "go/pkg1"},
+    {"prefix": "pkg2", "url": "https://github.com/example/monorepo", "subdir": "../pkg2"},
+    {"prefix": "pkg3", "url": "https://github.com/example/monorepo", "subdir": "/go/"}
+  ]
+}`,
+			expectedErr: `5:70: repositories[1].subdir (prefix "pkg2"): must be a relative path to a directory in the repository, got "../pkg2"
+6:70: repositories[2].subdir (prefix "pkg3"): must be a relative path to a directory in the repository, got "/go/"`,
+		},
+		{
+			description: "versions",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {
+      "prefix": "pkg1",
+      "url": "https://github.com/example/go-pkg1",
+      "subs": ["v2"],
+      "versions": [
+        "v2",
+        "v1",
+        "3",
+        {"version": "v4", "url": "github.com/example/go-pkg1-v4", "branch": "v 4"}
+      ]
+    }
+  ]
+}`,
+			expectedErr: `9:9: repositories[0].versions[0] (prefix "pkg1"): package "pkg1/v2" is also generated by repositories[0].subs[0]
+10:9: repositories[0].versions[1] (prefix "pkg1"): must be a major version of 2 or later, such as v2, got "v1"
+11:9: repositories[0].versions[2] (prefix "pkg1"): must be a major version of 2 or later, such as v2, got "3"
+12:27: repositories[0].versions[3].url (prefix "pkg1"): must be an absolute URL, got "github.com/example/go-pkg1-v4"
+12:67: repositories[0].versions[3].branch (prefix "pkg1"): must not contain spaces, got "v 4"`,
+		},
+		{
+			description: "discover without clone",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "discover": true}
+  ]
+}`,
+			expectedErr: `4:69: repositories[0].discover (prefix "pkg1"): requires clone to be set`,
+		},
+		{
+			description: "duplicate and overlapping packages",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "subs": ["sub1", "sub1"]},
+    {"prefix": "pkg1/sub1", "url": "https://github.com/example/go-pkg1-sub1"},
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1-again"}
+  ]
+}`,
+			expectedErr: `4:86: repositories[0].subs[1] (prefix "pkg1"): package "pkg1/sub1" is also generated by repositories[0].subs[0]
+5:6: repositories[1].prefix (prefix "pkg1/sub1"): package "pkg1/sub1" is also generated by repositories[0].subs[0]
+6:6: repositories[2].prefix (prefix "pkg1"): package "pkg1" is also generated by repositories[0].prefix`,
+		},
+		{
+			description: "yaml",
+			format:      FormatYAML,
+			config: `domain: example.com
+repositories:
+  - prefix: pkg1
+    url: https://github.com/example/go-pkg1
+    hiden: true
+  - prefix: pkg2
+`,
+			expectedErr: `5:5: repositories[0].hiden (prefix "pkg1"): unknown field "hiden"
+6:5: repositories[1].url (prefix "pkg2"): is required`,
+		},
+		{
+			description: "yaml after fields",
+			format:      FormatYAML,
+			config: `domain: example.com
+repositories:
+  - prefix: pkg1
+    url: https://github.com/example/go-pkg1
+  - prefix: pkg2
+    type: cvs
+`,
+			expectedErr: `5:5: repositories[1].url (prefix "pkg2"): is required
+6:5: repositories[1].type (prefix "pkg2"): unsupported type "cvs", must be one of git, hg, svn, bzr, fossil, mod`,
+		},
+		{
+			description: "toml",
+			format:      FormatTOML,
+			config: `domain = "example.com"
+
+[[repositories]]
+prefix = "pkg1"
+url = "https://github.com/example/go-pkg1"
+hiden = true
+`,
+			expectedErr: `6:1: repositories[0].hiden (prefix "pkg1"): unknown field "hiden"`,
+		},
+		{
+			description: "toml positions",
+			format:      FormatTOML,
+			config: `domain = "example.com" # "not = a key"
+description = """
+[[repositories]]
+"""
+
+[[repositories]]
+prefix = "pkg1"
+url = "https://github.com/example/go-pkg1"
+subs = [
+  "sub1",
+  { name = "sub2", hiden = true },
+]
+
+[[repositories]]
+"prefix" = 'pkg2'
+url = "github.com/example/go-pkg2"
+website.url = "/home"
+
+[repositories.source]
+home = "https://example.com/go-pkg2"
+
+[[repositories.versions]]
+version = "2"
+`,
+			expectedErr: `2:1: description: unknown field "description"
+11:20: repositories[0].subs[1].hiden (prefix "pkg1"): unknown field "hiden"
+16:1: repositories[1].url (prefix "pkg2"): must be an absolute URL, got "github.com/example/go-pkg2"
+17:1: repositories[1].website.url (prefix "pkg2"): must be an absolute URL, got "/home"
+22:1: repositories[1].versions[0] (prefix "pkg2"): must be a major version of 2 or later, such as v2, got "2"`,
+		},
+		{
+			description: "json syntax",
+			format:      FormatJSON,
+			config: `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1",}
+  ]
+}`,
+			expectedErr: `4:23: invalid character '}' looking for beginning of object key string`,
+		},
+	}
+
+	for _, tc := range testCases {
+		_, err := ParseConfigFormat(strings.NewReader(tc.config), tc.format)
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Errorf("Test case %q got err:\n%v\nwant no error", tc.description, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("Test case %q got no error, want:\n%s", tc.description, tc.expectedErr)
+			continue
+		}
+		if g, w := err.Error(), tc.expectedErr; g != w {
+			t.Errorf("Test case %q got err:\n%s\nwant:\n%s", tc.description, g, w)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{Prefix: "pkg1", URL: "https://github.com/example/go-pkg1"},
+			{Prefix: "pkg2"},
+		},
+	}
+
+	err := c.Validate()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Got err %#v, want *ValidationError", err)
+	}
+	expected := []Problem{
+		{Path: "repositories[1].url", Repository: 1, Prefix: "pkg2", Message: "is required"},
+	}
+	if len(validationErr.Problems) != len(expected) || validationErr.Problems[0] != expected[0] {
+		t.Errorf("Got problems %#v, want %#v", validationErr.Problems, expected)
+	}
+
+	c.Repositories[1].URL = "https://github.com/example/go-pkg2"
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("Got err %v, want no error", err)
+	}
+}
+
+func TestParseConfigPackagePaths(t *testing.T) {
+	testCases := []struct {
+		prefix      string
+		sub         string
+		expectedErr string
+	}{
+		{prefix: "pkg1", sub: "sub1/subsub1"},
+		{prefix: "pkg1/v2", sub: "sub-1.x_y~z"},
+		{prefix: "../../etc", expectedErr: `invalid package path "../../etc": invalid path element ".."`},
+		{prefix: "/etc", expectedErr: `invalid package path "/etc": leading slash`},
+		{prefix: "pkg1/", expectedErr: `invalid package path "pkg1/": trailing slash`},
+		{prefix: "pkg1//sub", expectedErr: `invalid package path "pkg1//sub": double slash`},
+		{prefix: "./pkg1", expectedErr: `invalid package path "./pkg1": invalid path element "."`},
+		{prefix: `pkg1\sub`, expectedErr: `invalid package path "pkg1\\sub": invalid char '\\'`},
+		{prefix: "pkg 1", expectedErr: `invalid package path "pkg 1": invalid char ' '`},
+		{prefix: "pkg1", sub: "../pkg2", expectedErr: `invalid package path "../pkg2": invalid path element ".."`},
+		{prefix: "pkg1", sub: "/sub", expectedErr: `invalid package path "/sub": leading slash`},
+		{prefix: "pkg1", sub: "sub?x", expectedErr: `invalid package path "sub?x": invalid char '?'`},
+	}
+
+	for _, tc := range testCases {
+		c := Config{
+			Domain: "example.com",
+			Repositories: []Repository{
+				{Prefix: tc.prefix, URL: "https://github.com/example/go-pkg1"},
+			},
+		}
+		if tc.sub != "" {
+			c.Repositories[0].Subs = []Sub{{Name: tc.sub}}
+		}
+
+		err := c.Validate()
+		if tc.expectedErr == "" {
+			if err != nil {
+				t.Errorf("Prefix %q sub %q got err %v, want no error", tc.prefix, tc.sub, err)
+			}
+			continue
+		}
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Problems) != 1 {
+			t.Errorf("Prefix %q sub %q got err %v, want one problem", tc.prefix, tc.sub, err)
+			continue
+		}
+		if g, w := validationErr.Problems[0].Message, tc.expectedErr; g != w {
+			t.Errorf("Prefix %q sub %q got problem %q, want %q", tc.prefix, tc.sub, g, w)
+		}
+	}
+}
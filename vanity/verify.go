@@ -0,0 +1,102 @@
+package vanity
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// VerifyClones checks that the local clones configured with Clone contain
+// the modules and packages the config generates pages for. Clone paths that
+// are relative are relative to dir, which is usually the directory containing
+// the config file. Repositories without a Clone are not checked.
+//
+// The go.mod at the root of each clone, or at Subdir in the clone, must
+// declare the module path made of the domain and the repository prefix.
+// Versions developed in a major version subdirectory must have a go.mod in
+// the subdirectory declaring the module path with the version. Every sub
+// must be a directory in the module that contains Go files.
+//
+// A message is returned for each mismatch found. An error is returned if a
+// clone cannot be read.
+func (c Config) VerifyClones(dir string) ([]string, error) {
+	var mismatches []string
+	for _, r := range c.Repositories {
+		if r.Clone == "" {
+			continue
+		}
+		clone := r.Clone
+		if !filepath.IsAbs(clone) {
+			clone = filepath.Join(dir, clone)
+		}
+		if _, err := os.Stat(clone); err != nil {
+			return nil, fmt.Errorf("repository %q: reading clone: %w", r.Prefix, err)
+		}
+
+		type mod struct {
+			dir, path string
+		}
+		root := filepath.Join(clone, filepath.FromSlash(r.Subdir))
+		modules := []mod{{root, path.Join(c.Domain, r.Prefix)}}
+		for i, v := range r.Versions {
+			// Versions on their own branch or in their own repository are
+			// not in the clone.
+			if v.Branch != "" || v.URL != "" {
+				continue
+			}
+			modules = append(modules, mod{filepath.Join(root, v.Version), path.Join(c.Domain, r.VersionPath(i))})
+		}
+		for _, m := range modules {
+			mismatch, err := verifyModulePath(m.dir, m.path)
+			if err != nil {
+				return nil, fmt.Errorf("repository %q: %w", r.Prefix, err)
+			}
+			if mismatch != "" {
+				mismatches = append(mismatches, fmt.Sprintf("%s: %s", path.Join(c.Domain, r.Prefix), mismatch))
+			}
+		}
+
+		for _, s := range r.Subs {
+			subDir := filepath.Join(root, filepath.FromSlash(s.Name))
+			ok := false
+			if fi, err := os.Stat(subDir); err == nil && fi.IsDir() {
+				ok, err = isPackageDir(subDir)
+				if err != nil {
+					return nil, fmt.Errorf("repository %q: sub %q: %w", r.Prefix, s.Name, err)
+				}
+			}
+			if !ok {
+				mismatches = append(mismatches, fmt.Sprintf("%s: sub %q has no Go files in %s", path.Join(c.Domain, r.Prefix), s.Name, subDir))
+			}
+		}
+	}
+	return mismatches, nil
+}
+
+// verifyModulePath returns a message describing the mismatch if the go.mod in
+// dir does not declare the module path, or an empty string if it does.
+func verifyModulePath(dir, modulePath string) (string, error) {
+	name := filepath.Join(dir, "go.mod")
+	data, err := os.ReadFile(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Sprintf("no go.mod in %s, want module %s", dir, modulePath), nil
+	} else if err != nil {
+		return "", err
+	}
+	f, err := modfile.ParseLax(name, data, nil)
+	if err != nil {
+		return "", err
+	}
+	if f.Module == nil {
+		return fmt.Sprintf("%s has no module directive, want module %s", name, modulePath), nil
+	}
+	if f.Module.Mod.Path != modulePath {
+		return fmt.Sprintf("%s declares module %s, want module %s", name, f.Module.Mod.Path, modulePath), nil
+	}
+	return "", nil
+}
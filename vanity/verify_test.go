@@ -0,0 +1,71 @@
+package vanity
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestVerifyClones(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"good/go.mod":           "module example.com/pkg1\n\ngo 1.21\n",
+		"good/sub1/sub1.go":     "package sub1\n",
+		"good/v2/go.mod":        "module example.com/pkg1/v2\n",
+		"wrong/go.mod":          "module github.com/example/go-pkg2\n",
+		"wrong/v2/go.mod":       "module example.com/pkg2\n",
+		"wrong/tests/x_test.go": "package tests\n",
+		"wrong/docs/README.md":  "docs\n",
+		"nomod/nomod.go":        "package nomod\n",
+		"mono/go/go.mod":        "module example.com/pkg4\n",
+		"mono/go/sub1/sub1.go":  "package sub1\n",
+	})
+
+	c := Config{
+		Domain: "example.com",
+		Repositories: []Repository{
+			{
+				Prefix:   "pkg1",
+				Clone:    "good",
+				Subs:     []Sub{{Name: "sub1"}},
+				Versions: []Version{{Version: "v2"}, {Version: "v3", Branch: "v3"}},
+			},
+			{
+				Prefix:   "pkg2",
+				Clone:    "wrong",
+				Subs:     []Sub{{Name: "tests"}, {Name: "docs"}, {Name: "missing"}},
+				Versions: []Version{{Version: "v2"}},
+			},
+			{Prefix: "pkg3", Clone: "nomod"},
+			{Prefix: "pkg4", Clone: "mono", Subdir: "go", Subs: []Sub{{Name: "sub1"}}},
+			{Prefix: "pkg5"},
+		},
+	}
+
+	mismatches, err := c.VerifyClones(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"example.com/pkg2: " + filepath.Join(dir, "wrong", "go.mod") + " declares module github.com/example/go-pkg2, want module example.com/pkg2",
+		"example.com/pkg2: " + filepath.Join(dir, "wrong", "v2", "go.mod") + " declares module example.com/pkg2, want module example.com/pkg2/v2",
+		`example.com/pkg2: sub "tests" has no Go files in ` + filepath.Join(dir, "wrong", "tests"),
+		`example.com/pkg2: sub "docs" has no Go files in ` + filepath.Join(dir, "wrong", "docs"),
+		`example.com/pkg2: sub "missing" has no Go files in ` + filepath.Join(dir, "wrong", "missing"),
+		"example.com/pkg3: no go.mod in " + filepath.Join(dir, "nomod") + ", want module example.com/pkg3",
+	}
+	if !reflect.DeepEqual(mismatches, expected) {
+		t.Errorf("Got mismatches:\n%q\nwant:\n%q", mismatches, expected)
+	}
+}
+
+func TestVerifyClonesMissing(t *testing.T) {
+	c := Config{
+		Domain:       "example.com",
+		Repositories: []Repository{{Prefix: "pkg1", Clone: "missing"}},
+	}
+	_, err := c.VerifyClones(t.TempDir())
+	if err == nil {
+		t.Errorf("Got no error, want error")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("vangen verify", flag.ExitOnError)
+	filename := flags.String("config", "vangen.json", "vangen configuration `filename`")
+	configFormat := flags.String("config-format", "", configFormatUsage)
+	clones := flags.String("clones", "", "`directory` containing clones of the repositories that have no clone configured, each named the same as the last element of the repository url")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Verify checks that the go.mod in the clone of each repository declares the module path vangen generates pages for, and that every sub is a directory containing Go files.\n\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\n")
+		fmt.Fprintf(os.Stderr, "  vangen verify [-config=vangen.json] [-clones=directory]\n\n")
+		fmt.Fprintf(os.Stderr, "Flags:\n\n")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	// Verify checks the clones against the config as written, so the clones
+	// are not read for branches or discovered subs.
+	c, err := parseConfigFile(*filename, *configFormat)
+	if err != nil {
+		return err
+	}
+
+	if *clones != "" {
+		dir, err := filepath.Abs(*clones)
+		if err != nil {
+			return err
+		}
+		for i := range c.Repositories {
+			r := &c.Repositories[i]
+			if r.Clone == "" {
+				r.Clone = filepath.Join(dir, cloneName(r.URL))
+			}
+		}
+	}
+
+	verified := 0
+	for _, r := range c.Repositories {
+		if r.Clone != "" {
+			verified++
+		}
+	}
+	if verified == 0 {
+		return errors.New("no repositories have a clone to verify, set clone on repositories or use -clones")
+	}
+
+	mismatches, err := c.VerifyClones(filepath.Dir(*filename))
+	if err != nil {
+		return err
+	}
+	for _, m := range mismatches {
+		fmt.Println(m)
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("%d mismatches found between the config and the clones", len(mismatches))
+	}
+	return nil
+}
+
+// cloneName returns the name of the directory that git and other VCS tools
+// clone the repository at repoURL into by default.
+func cloneName(repoURL string) string {
+	p := repoURL
+	if u, err := url.Parse(repoURL); err == nil {
+		p = u.Path
+	}
+	return path.Base(strings.TrimSuffix(strings.TrimSuffix(p, "/"), ".git"))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneName(t *testing.T) {
+	testCases := []struct {
+		url          string
+		expectedName string
+	}{
+		{url: "https://github.com/example/go-pkg1", expectedName: "go-pkg1"},
+		{url: "https://github.com/example/go-pkg1/", expectedName: "go-pkg1"},
+		{url: "https://gitlab.com/example/team/go-pkg1.git", expectedName: "go-pkg1"},
+		{url: "https://hg.sr.ht/~example/go-pkg1", expectedName: "go-pkg1"},
+	}
+
+	for _, tc := range testCases {
+		if g, w := cloneName(tc.url), tc.expectedName; g != w {
+			t.Errorf("URL %q got name %q, want %q", tc.url, g, w)
+		}
+	}
+}
+
+func TestVerifyClonesDirectory(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "vangen.json")
+	clonesDir := filepath.Join(dir, "src")
+	writeFiles(t, dir, map[string]string{
+		"vangen.json": `{
+  "domain": "example.com",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "subs": ["sub1"]}
+  ]
+}`,
+		"src/go-pkg1/go.mod":       "module example.com/pkg1\n",
+		"src/go-pkg1/sub1/sub1.go": "package sub1\n",
+	})
+
+	err := runVerify([]string{"-config", configFile, "-clones", clonesDir})
+	if err != nil {
+		t.Errorf("Got err %v, want nil", err)
+	}
+
+	err = os.WriteFile(filepath.Join(clonesDir, "go-pkg1", "go.mod"), []byte("module github.com/example/go-pkg1\n"), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = runVerify([]string{"-config", configFile, "-clones", clonesDir})
+	if g, w := err, "1 mismatches found between the config and the clones"; g == nil || g.Error() != w {
+		t.Errorf("Got err %v, want %s", g, w)
+	}
+
+	err = runVerify([]string{"-config", configFile})
+	if err == nil {
+		t.Errorf("Got no error without clones, want error")
+	}
+}
+
+func TestVerifyDetachedClone(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "vangen.json")
+	writeFiles(t, dir, map[string]string{
+		"vangen.json": `{
+  "domain": "example.com",
+  "branch": "clone",
+  "repositories": [
+    {"prefix": "pkg1", "url": "https://github.com/example/go-pkg1", "clone": "go-pkg1", "subs": ["sub1"]}
+  ]
+}`,
+		"go-pkg1/.git/HEAD":    "0123456789abcdef0123456789abcdef01234567\n",
+		"go-pkg1/go.mod":       "module example.com/pkg1\n",
+		"go-pkg1/sub1/sub1.go": "package sub1\n",
+	})
+
+	err := runVerify([]string{"-config", configFile})
+	if err != nil {
+		t.Errorf("Got err %v, want nil", err)
+	}
+}